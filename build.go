@@ -0,0 +1,175 @@
+package venv
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Python implementations told apart by the names of the environments.
+const (
+	CPython = "cpython"
+	PyPy    = "pypy"
+	GraalPy = "graalpy"
+)
+
+// probeScript prints the implementation, the version and the ABI flags of the
+// interpreter running it, or - if there are none, then the base interpreter
+// itself. It must run on Python 2 as well. Windows has no sys.abiflags, so
+// free-threaded builds are told by their configuration there.
+const probeScript = `import platform, sys, sysconfig
+print(platform.python_implementation().lower())
+print(".".join(str(n) for n in sys.version_info[:3]))
+flags = getattr(sys, "abiflags", None)
+if flags is None:
+    flags = sysconfig.get_config_var("Py_GIL_DISABLED") and "t" or ""
+print(flags or "-")
+print(getattr(sys, "_base_executable", None) or sys.executable)`
+
+// versionTag matches the version suffixes of environment names, e.g. py3.12,
+// py3.13t or pypy3.10.
+var versionTag = regexp.MustCompile(`^(py|pypy|graalpy)([0-9]+(?:\.[0-9]+)*)([a-z]*)$`)
+
+// PythonBuild identifies the interpreter an environment is created with.
+type PythonBuild struct {
+	// Implementation is cpython, pypy, graalpy...
+	Implementation string `json:"implementation"`
+	// Version is the full version of the interpreter, e.g. 3.12.4.
+	Version string `json:"version"`
+	// ABIFlags are the flags of sys.abiflags, e.g. t for free-threaded builds.
+	ABIFlags string `json:"abi_flags,omitempty"`
+}
+
+// ProbePython runs the python executable to find its build.
+func ProbePython(executable string) (PythonBuild, error) {
+	build, _, err := probePython(executable)
+	return build, err
+}
+
+// probePython runs the python executable to find its build and the base
+// interpreter behind it, which differs from executable for shims such as
+// pyenv's and for the python of an environment. The base interpreter is empty
+// if the executable doesn't tell it.
+func probePython(executable string) (PythonBuild, string, error) {
+	output, err := exec.Command(executable, "-c", probeScript).Output()
+	if err != nil {
+		return PythonBuild{}, "", err
+	}
+	// the path of the base interpreter may contain spaces
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	if len(lines) < 2 || len(lines) > 4 || !versionNumber.MatchString(lines[1]) {
+		return PythonBuild{}, "", fmt.Errorf("'%s' is not a Python interpreter.", executable)
+	}
+	build := PythonBuild{Implementation: lines[0], Version: lines[1]}
+	if len(lines) >= 3 && lines[2] != "-" {
+		build.ABIFlags = lines[2]
+	}
+	base := ""
+	if len(lines) == 4 {
+		base = lines[3]
+	}
+	return build, base, nil
+}
+
+// Tag returns the suffix naming the environments of the build: py followed
+// by the minor version for CPython, prefixed by the implementation otherwise,
+// and followed by the flags changing the ABI. E.g. py3.12, py3.13t, pypy3.10.
+func (b PythonBuild) Tag() string {
+	prefix := VersionPrefix
+	if b.Implementation == PyPy || b.Implementation == GraalPy {
+		prefix = b.Implementation
+	}
+	return prefix + getMinorVersion(b.Version) + b.tagFlags()
+}
+
+// Label returns the description of the build shown to users, e.g. 3.12.4,
+// 3.13.0t or pypy3.10.14.
+func (b PythonBuild) Label() string {
+	label := b.Version + b.tagFlags()
+	if b.Implementation != "" && b.Implementation != CPython {
+		label = b.Implementation + label
+	}
+	return label
+}
+
+// tagFlags returns the ABI flags told apart by the names of the environments:
+// free-threaded and debug builds. The pymalloc flag of Python < 3.8 is not.
+func (b PythonBuild) tagFlags() string {
+	flags := ""
+	for _, f := range "td" {
+		if strings.ContainsRune(b.ABIFlags, f) {
+			flags += string(f)
+		}
+	}
+	return flags
+}
+
+// ParseVersionTag returns the build named by the version suffix of an
+// environment, as returned by ExtractVersion. The version has no patch level.
+// The boolean is false if tag is not a version suffix.
+func ParseVersionTag(tag string) (PythonBuild, bool) {
+	match := versionTag.FindStringSubmatch(tag)
+	if match == nil {
+		return PythonBuild{}, false
+	}
+	build := PythonBuild{Implementation: match[1], Version: match[2], ABIFlags: match[3]}
+	if build.Implementation == VersionPrefix {
+		build.Implementation = CPython
+	}
+	return build, true
+}
+
+// CompareBuilds orders builds by implementation, CPython first, then by
+// version, then by ABI flags.
+func CompareBuilds(a, b PythonBuild) int {
+	if a.Implementation != b.Implementation {
+		switch {
+		case a.Implementation == CPython:
+			return -1
+		case b.Implementation == CPython:
+			return 1
+		}
+		return strings.Compare(a.Implementation, b.Implementation)
+	}
+	if c := compareVersions(versionParts(a.Version), versionParts(b.Version)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.tagFlags(), b.tagFlags())
+}
+
+// CompareVersionTags orders version suffixes like CompareBuilds.
+// Suffixes that cannot be parsed come last.
+func CompareVersionTags(a, b string) int {
+	buildA, okA := ParseVersionTag(a)
+	buildB, okB := ParseVersionTag(b)
+	switch {
+	case okA && okB:
+		return CompareBuilds(buildA, buildB)
+	case okA:
+		return -1
+	case okB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// preferredVersionTag returns the highest of the version suffixes, regular
+// CPython builds being preferred to the others.
+func preferredVersionTag(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	tags = slices.Clone(tags)
+	slices.SortFunc(tags, CompareVersionTags)
+	for i := len(tags) - 1; i >= 0; i-- {
+		if build, ok := ParseVersionTag(tags[i]); ok && build.Implementation == CPython && build.tagFlags() == "" {
+			return tags[i]
+		}
+	}
+	return tags[len(tags)-1]
+}
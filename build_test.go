@@ -0,0 +1,56 @@
+package venv
+
+import (
+	"testing"
+)
+
+func TestVersionTags_TellBuildsApart(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		build PythonBuild
+		name  string
+		tag   string
+		label string
+	}{
+		{PythonBuild{Implementation: CPython, Version: "3.12.4"}, "lib-py3.12", "py3.12", "3.12.4"},
+		{PythonBuild{Implementation: CPython, Version: "3.13.0", ABIFlags: "t"}, "lib-py3.13t", "py3.13t", "3.13.0t"},
+		{PythonBuild{Implementation: CPython, Version: "3.7.17", ABIFlags: "m"}, "lib-py3.7", "py3.7", "3.7.17"},
+		{PythonBuild{Implementation: PyPy, Version: "3.10.14"}, "lib-pypy3.10", "pypy3.10", "pypy3.10.14"},
+		{PythonBuild{Implementation: GraalPy, Version: "3.11.7"}, "my-lib-graalpy3.11", "graalpy3.11", "graalpy3.11.7"},
+	}
+	for _, tt := range tests {
+		if got := tt.build.Tag(); got != tt.tag {
+			t.Errorf("%+v: want tag %s, got %s", tt.build, tt.tag, got)
+		}
+		if got := tt.build.Label(); got != tt.label {
+			t.Errorf("%+v: want label %s, got %s", tt.build, tt.label, got)
+		}
+		name, tag := ExtractVersion(tt.name)
+		if tag != tt.tag || name+"-"+tag != tt.name {
+			t.Errorf("%s: want version %s, got name %s and version %s", tt.name, tt.tag, name, tag)
+		}
+		build, ok := ParseVersionTag(tag)
+		if !ok || build.Implementation != tt.build.Implementation || build.Tag() != tt.tag {
+			t.Errorf("%s: want %s build, got %+v", tag, tt.build.Implementation, build)
+		}
+	}
+}
+
+func TestPreferredVersionTag_PrefersRegularCPython(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{[]string{"py3.9", "py3.12", "py3.10"}, "py3.12"},
+		{[]string{"py3.13t", "pypy3.11", "py3.12"}, "py3.12"},
+		{[]string{"py3.13t", "pypy3.10"}, "pypy3.10"},
+		{[]string{"py3.13t"}, "py3.13t"},
+		{[]string{}, ""},
+	}
+	for _, tt := range tests {
+		if got := preferredVersionTag(tt.tags); got != tt.want {
+			t.Errorf("%v: want %s, got %s", tt.tags, tt.want, got)
+		}
+	}
+}
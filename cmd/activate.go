@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 
 	venv "github.com/azr4e1/venv-notary"
 	"github.com/azr4e1/venv-notary/graphics"
@@ -37,9 +38,15 @@ func activateCobraFunction(cmd *cobra.Command, args []string) error {
 }
 
 func activateGlobal(notary venv.Notary, cmd *cobra.Command, args []string) error {
-	err := notary.ActivateGlobal(globalVenvName, pythonVersion)
+	err := activateGlobalVenv(cmd, notary)
 	if err != nil {
-		if errors.As(err, &venv.VenvNotRegisteredError{}) {
+		if errors.As(err, &venv.VenvNotRegisteredError{}) && cfg.Bool("auto_create") {
+			if pythonVersion == "" {
+				pythonVersion, err = defaultPython(false)
+				if err != nil {
+					return err
+				}
+			}
 			// err = notary.CreateGlobal(name, pythonVersion)
 			err = graphics.StatusMain("No environment registered with this name and this Python version. Creating it now...", "Environment successfully created.", createAction, nil)(cmd, args)
 			if err != nil {
@@ -49,7 +56,7 @@ func activateGlobal(notary venv.Notary, cmd *cobra.Command, args []string) error
 			if err != nil {
 				return err
 			}
-			err = notary.ActivateGlobal(globalVenvName, pythonVersion)
+			err = activateGlobalVenv(cmd, notary)
 			return err
 		}
 		return err
@@ -58,9 +65,15 @@ func activateGlobal(notary venv.Notary, cmd *cobra.Command, args []string) error
 }
 
 func activateLocal(notary venv.Notary, cmd *cobra.Command, args []string) error {
-	err := notary.ActivateLocal(pythonVersion)
+	err := activateLocalVenv(cmd, notary)
 	if err != nil {
-		if errors.As(err, &venv.VenvNotRegisteredError{}) {
+		if errors.As(err, &venv.VenvNotRegisteredError{}) && cfg.Bool("auto_create") {
+			if pythonVersion == "" {
+				pythonVersion, err = defaultPython(true)
+				if err != nil {
+					return err
+				}
+			}
 			// err = notary.CreateLocal(pythonVersion)
 			err = graphics.StatusMain("No environment registered at this location and with this Python version. Creating it now...", "Environment successfully created.", createAction, nil)(cmd, args)
 			if err != nil {
@@ -70,7 +83,7 @@ func activateLocal(notary venv.Notary, cmd *cobra.Command, args []string) error
 			if err != nil {
 				return err
 			}
-			err = notary.ActivateLocal(pythonVersion)
+			err = activateLocalVenv(cmd, notary)
 			return err
 		}
 		return err
@@ -78,8 +91,43 @@ func activateLocal(notary venv.Notary, cmd *cobra.Command, args []string) error
 	return nil
 }
 
+// activateGlobalVenv activates the global environment in a new shell, or prints
+// the code activating it in the current shell.
+func activateGlobalVenv(cmd *cobra.Command, notary venv.Notary) error {
+	if printActivation {
+		code, err := notary.ActivationCodeGlobal(globalVenvName, pythonVersion)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), code)
+		return nil
+	}
+	return notary.ActivateGlobal(globalVenvName, pythonVersion)
+}
+
+// activateLocalVenv activates the local environment in a new shell, or prints
+// the code activating it in the current shell.
+func activateLocalVenv(cmd *cobra.Command, notary venv.Notary) error {
+	dir, err := projectDir()
+	if err != nil {
+		return err
+	}
+	if printActivation {
+		code, err := notary.ActivationCodeLocal(dir, localVenvName, pythonVersion)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), code)
+		return nil
+	}
+	return notary.ActivateLocal(dir, localVenvName, pythonVersion)
+}
+
 func init() {
 	activateCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "activate global venv")
 	activateCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "use this python version")
+	activateCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "activate a named local venv")
+	activateCmd.Flags().BoolVar(&printActivation, "print", false, "print the activation code for the current shell instead of starting a new one")
+	activateCmd.MarkFlagsMutuallyExclusive("global", "name")
 	activateCmd.RegisterFlagCompletionFunc("global", venvCompletion)
 }
@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"fmt"
 	"path/filepath"
-
 	"regexp"
 
 	venv "github.com/azr4e1/venv-notary"
@@ -26,13 +26,13 @@ func cleanAction(cmd *cobra.Command, args []string) func() error {
 			return err
 		}
 		if localVenv {
-			err = deleteVenv(notary.ListLocal(), pythonVersion, namePattern)
+			err = deleteVenv(&notary, notary.ListLocal(), pythonVersion, namePattern)
 			if err != nil {
 				return err
 			}
 		}
 		if globalVenv {
-			err = deleteVenv(notary.ListGlobal(), pythonVersion, namePattern)
+			err = deleteVenv(&notary, notary.ListGlobal(), pythonVersion, namePattern)
 			if err != nil {
 				return err
 			}
@@ -41,34 +41,48 @@ func cleanAction(cmd *cobra.Command, args []string) func() error {
 	}
 }
 
-func deleteVenv(vPath []string, python, namePattern string) error {
-	var version string
+func deleteVenv(notary *venv.Notary, vPath []string, python, namePattern string) error {
+	vPath, err := filterVenvs(*notary, vPath, python, namePattern)
+	if err != nil {
+		return err
+	}
+	for _, venvPath := range vPath {
+		err = notary.Delete(venv.Venv{Path: venvPath})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterVenvs returns the environments of vPath created with python, a version
+// specifier or an executable, and with a name matching namePattern. Empty
+// filters match everything.
+func filterVenvs(notary venv.Notary, vPath []string, python, namePattern string) ([]string, error) {
 	var err error
 	var nameRegexp *regexp.Regexp
 	if namePattern != "" {
-		nameRegexp = regexp.MustCompile(namePattern)
-	}
-	if python != "" {
-		version, err = venv.PythonVersion(python)
+		nameRegexp, err = regexp.Compile(namePattern)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("Invalid name pattern '%s': %w", namePattern, err)
 		}
 	}
+	matches, err := notary.PythonMatcher(python)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []string{}
 	for _, venvPath := range vPath {
-		v := venv.Venv{Path: venvPath}
-		name, venvVersion := venv.ExtractVersion(filepath.Base(venvPath))
-		if version != "" && version != venvVersion {
+		name, _ := venv.ExtractVersion(filepath.Base(venvPath))
+		if !matches(venvPath) {
 			continue
 		}
 		if nameRegexp != nil && !nameRegexp.MatchString(name) {
 			continue
 		}
-		err = v.Delete()
-		if err != nil {
-			return err
-		}
+		filtered = append(filtered, venvPath)
 	}
-	return nil
+	return filtered, nil
 }
 
 func init() {
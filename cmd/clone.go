@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/graphics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneCmd = &cobra.Command{
+		Use:   "clone [destination]",
+		Short: "Clone an environment into a new global environment, or into the local environment if no destination is given",
+		Long: `Clone an environment, with the same Python interpreter and installed packages, into a new independent environment.
+
+The source is the global environment selected with -g, or otherwise the local environment that run would use, or the named one selected with -n. The destination is the global environment named by the argument, or the local environment of the current directory if no argument is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: graphics.StatusMain("Cloning environment...", "Environment successfully cloned.", cloneAction, nil),
+	}
+)
+
+func cloneAction(cmd *cobra.Command, args []string) func() error {
+	return func() error {
+		notary, err := venv.NewNotary()
+		if err != nil {
+			return err
+		}
+		currDir, err := projectDir()
+		if err != nil {
+			return err
+		}
+		var src venv.Venv
+		if globalVenvName != "" {
+			src, err = notary.FindGlobal(globalVenvName, pythonVersion)
+		} else {
+			src, err = resolveLocal(notary)
+		}
+		if err != nil {
+			return err
+		}
+		var dst venv.Venv
+		var project string
+		if len(args) == 1 {
+			dst, err = notary.GetGlobalVenv(args[0], "")
+		} else {
+			if globalVenvName == "" && localVenvName == "" {
+				return errors.New("Source and destination are the same environment. Provide a destination name.")
+			}
+			dst, err = notary.GetLocalVenv(currDir, "", "")
+			project = currDir
+		}
+		if err != nil {
+			return err
+		}
+		return notary.Clone(src, dst, project)
+	}
+}
+
+func init() {
+	cloneCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "clone this global venv")
+	cloneCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "clone the venv with this python version")
+	cloneCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "clone a named local venv")
+	cloneCmd.MarkFlagsMutuallyExclusive("global", "name")
+	cloneCmd.RegisterFlagCompletionFunc("global", venvCompletion)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	venv "github.com/azr4e1/venv-notary"
+)
+
+func TestClone_ClonesTheLocalVenvOfRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake environments are posix ones")
+	}
+	t.Setenv(venv.HomeEnv, t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("VIRTUAL_ENV", "")
+	notary, err := venv.NewNotary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the project pins the older of its two versions
+	project := t.TempDir()
+	err = os.WriteFile(filepath.Join(project, venv.ProjectFile), []byte("python = \"3.11\"\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for variant, versions := range map[string][]string{"": {"py3.11", "py3.12"}, "docs": {"py3.11", "py3.13"}} {
+		v, err := notary.GetLocalVenv(project, variant, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, version := range versions {
+			local := venv.Venv{Path: v.Path + "-" + version}
+			fakeVenv(t, local.Path)
+			err = os.WriteFile(filepath.Join(local.Path, "pyvenv.cfg"), []byte("home = /usr/bin\n"), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = local.WriteMetadata(venv.Metadata{Project: project})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// the status line of clone needs a terminal, so its action is run directly
+	clone := func(name, python string, args ...string) error {
+		projectFlag, localVenvName, pythonVersion = project, name, python
+		return cloneAction(cloneCmd, args)()
+	}
+	t.Cleanup(func() { projectFlag, localVenvName, pythonVersion = "", "", "" })
+
+	tests := []struct {
+		name string
+		dst  string
+		want string
+	}{
+		{"", "pinned", "pinned-py3.11"},
+		{"docs", "docs", "docs-py3.11"},
+	}
+	for _, tt := range tests {
+		err := clone(tt.name, "", tt.dst)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dst, err)
+		}
+		if !(venv.Venv{Path: filepath.Join(notary.GlobalDir(), tt.want)}).IsVenv() {
+			t.Errorf("want the global venv %s", tt.want)
+		}
+	}
+
+	// a variant can be cloned into the default local venv
+	err = clone("docs", "3.13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := notary.GetLocalVenv(project, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(venv.Venv{Path: v.Path + "-py3.13"}).IsVenv() {
+		t.Error("want the docs venv cloned into the default local venv")
+	}
+	err = clone("", "")
+	if err == nil {
+		t.Error("want an error cloning the local venv into itself, got nil")
+	}
+}
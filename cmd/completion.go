@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// cobra only generates completion scripts for bash, zsh, fish and powershell;
+// the completers of the other shells call the hidden __complete command of
+// cobra instead.
+
+const nushellCompletion = `# vn completion for nushell. Add it to config.nu; if you already have an
+# external completer, call vn_completer from it for the vn command.
+let vn_completer = {|spans|
+    ^vn __complete ...($spans | skip 1)
+    | lines
+    | where {|line| not ($line | str starts-with ':') }
+    | each {|line|
+        let parts = $line | split row "\t"
+        {value: $parts.0, description: (if ($parts | length) > 1 { $parts.1 } else { '' })}
+    }
+}
+
+$env.config.completions.external = {
+    enable: true
+    completer: {|spans|
+        if $spans.0 == 'vn' { do $vn_completer $spans }
+    }
+}
+`
+
+const elvishCompletion = `# vn completion for elvish. Add it to rc.elv.
+use str
+
+set edit:completion:arg-completer[vn] = {|@words|
+    vn __complete $@words[1..] | each {|line|
+        if (not (str:has-prefix $line ':')) {
+            var parts = [(str:split "\t" $line)]
+            edit:complex-candidate $parts[0]
+        }
+    }
+}
+`
+
+var (
+	nushellCompletionCmd = &cobra.Command{
+		Use:   "nushell",
+		Short: "Generate the autocompletion script for nushell",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprint(cmd.OutOrStdout(), nushellCompletion)
+		},
+	}
+	elvishCompletionCmd = &cobra.Command{
+		Use:   "elvish",
+		Short: "Generate the autocompletion script for elvish",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprint(cmd.OutOrStdout(), elvishCompletion)
+		},
+	}
+)
+
+// addCompletionCmds adds the completion scripts of the shells cobra doesn't
+// support to the default completion command.
+func addCompletionCmds() {
+	rootCmd.InitDefaultCompletionCmd()
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "completion" {
+			c.AddCommand(nushellCompletionCmd, elvishCompletionCmd)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/azr4e1/venv-notary/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Read and edit the configuration file",
+		Long: `Read and edit the configuration file.
+
+Every setting can also be overridden by an environment variable. Flags given on the command line take precedence over environment variables, which take precedence over the configuration file.`,
+	}
+	configListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the settings with their value and where the value comes from",
+		Args:  cobra.NoArgs,
+		RunE:  configListCobraFunction,
+	}
+	configGetCmd = &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print the value of a setting",
+		Args:              cobra.ExactArgs(1),
+		RunE:              configGetCobraFunction,
+		ValidArgsFunction: configKeyCompletion,
+	}
+	configSetCmd = &cobra.Command{
+		Use:               "set <key> <value>",
+		Short:             "Set a setting in the configuration file",
+		Args:              cobra.ExactArgs(2),
+		RunE:              configSetCobraFunction,
+		ValidArgsFunction: configKeyCompletion,
+	}
+	configUnsetCmd = &cobra.Command{
+		Use:               "unset <key>",
+		Short:             "Remove a setting from the configuration file",
+		Args:              cobra.ExactArgs(1),
+		RunE:              configUnsetCobraFunction,
+		ValidArgsFunction: configKeyCompletion,
+	}
+	configEditCmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Open the configuration file in $VISUAL or $EDITOR",
+		Args:  cobra.NoArgs,
+		RunE:  configEditCobraFunction,
+	}
+	configPathCmd = &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the configuration file",
+		Args:  cobra.NoArgs,
+		RunE:  configPathCobraFunction,
+	}
+)
+
+func configListCobraFunction(cmd *cobra.Command, args []string) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tORIGIN\tENV")
+	for _, s := range config.Settings {
+		value, origin := cfg.Value(s.Key)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Key, value, origin, s.Env)
+	}
+	return w.Flush()
+}
+
+func configGetCobraFunction(cmd *cobra.Command, args []string) error {
+	if _, err := cfg.Get(args[0]); err != nil {
+		return err
+	}
+	value, _ := cfg.Value(args[0])
+	fmt.Fprintln(cmd.OutOrStdout(), value)
+	return nil
+}
+
+func configSetCobraFunction(cmd *cobra.Command, args []string) error {
+	err := checkConfigDecoded(cmd)
+	if err != nil {
+		return err
+	}
+	err = cfg.Set(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return cfg.Save()
+}
+
+func configUnsetCobraFunction(cmd *cobra.Command, args []string) error {
+	err := checkConfigDecoded(cmd)
+	if err != nil {
+		return err
+	}
+	err = cfg.Set(args[0], "")
+	if err != nil {
+		return err
+	}
+	return cfg.Save()
+}
+
+// checkConfigDecoded refuses to save over a configuration file that could not
+// be decoded, which would lose its content.
+func checkConfigDecoded(cmd *cobra.Command) error {
+	if errors.As(configErr, &config.SyntaxError{}) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%w. Run 'vn config edit' to fix it.", configErr)
+	}
+	return nil
+}
+
+func configEditCobraFunction(cmd *cobra.Command, args []string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	editor := cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// the editor may come with arguments, e.g. code --wait
+	editorArgs := strings.Fields(editor)
+	editCmd := exec.Command(editorArgs[0], append(editorArgs[1:], path)...)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	err = editCmd.Run()
+	if err != nil {
+		return err
+	}
+	// report the problems left in the file
+	_, err = config.Load()
+	return err
+}
+
+func configPathCobraFunction(cmd *cobra.Command, args []string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), path)
+	return nil
+}
+
+func configKeyCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys := []string{}
+	for _, s := range config.Settings {
+		keys = append(keys, fmt.Sprintf("%s\t%s", s.Key, s.Usage))
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configPathCmd)
+}
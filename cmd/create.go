@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"strings"
+
 	venv "github.com/azr4e1/venv-notary"
 	"github.com/azr4e1/venv-notary/graphics"
 	"github.com/spf13/cobra"
 )
 
 var (
-	createCmd = &cobra.Command{
+	createOptions venv.CreateOptions
+	createCmd     = &cobra.Command{
 		Use:   "create",
 		Short: "Create a local or global virtual environment (default local)",
 		RunE:  graphics.StatusMain("Creating environment...", "Environment successfully created.", createAction, nil),
@@ -21,13 +24,28 @@ func createAction(cmd *cobra.Command, args []string) func() error {
 		if err != nil {
 			return err
 		}
+		if pythonVersion == "" {
+			pythonVersion, err = defaultPython(globalVenvName == "")
+			if err != nil {
+				return err
+			}
+		}
+		opts := createOptions
+		opts.Creator, err = preferredCreator(globalVenvName == "")
+		if err != nil {
+			return err
+		}
 		if globalVenvName != "" {
-			err = notary.CreateGlobal(globalVenvName, pythonVersion)
+			err = notary.CreateGlobalWith(globalVenvName, pythonVersion, opts)
 			if err != nil {
 				return err
 			}
 		} else {
-			err = notary.CreateLocal(pythonVersion)
+			dir, err := projectDir()
+			if err != nil {
+				return err
+			}
+			err = notary.CreateLocalWith(dir, localVenvName, pythonVersion, opts)
 			if err != nil {
 				return err
 			}
@@ -39,5 +57,15 @@ func createAction(cmd *cobra.Command, args []string) func() error {
 func init() {
 	createCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "create a global venv")
 	createCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "use this python version")
+	createCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "create a named local venv")
+	createCmd.Flags().StringVar(&creatorFlag, "creator", "", "create the venv with this backend: "+strings.Join(venv.CreatorNames(), ", "))
+	createCmd.Flags().BoolVar(&createOptions.SystemSitePackages, "system-site-packages", false, "give the venv access to the site-packages of python")
+	createCmd.Flags().BoolVar(&createOptions.WithoutPip, "without-pip", false, "do not install pip in the venv")
+	createCmd.Flags().BoolVar(&createOptions.Copies, "copies", false, "copy the python executable instead of linking it")
+	createCmd.Flags().BoolVar(&createOptions.UpgradeDeps, "upgrade-deps", false, "upgrade pip and its dependencies to their latest version")
+	createCmd.Flags().StringVar(&createOptions.Prompt, "prompt", "", "show this prompt instead of the venv name when active")
+	createCmd.MarkFlagsMutuallyExclusive("without-pip", "upgrade-deps")
+	createCmd.MarkFlagsMutuallyExclusive("global", "name")
 	createCmd.RegisterFlagCompletionFunc("global", venvCompletion)
+	createCmd.RegisterFlagCompletionFunc("creator", cobra.FixedCompletions(venv.CreatorNames(), cobra.ShellCompDirectiveNoFileComp))
 }
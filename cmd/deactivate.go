@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/azr4e1/venv-notary/shell"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deactivateCmd = &cobra.Command{
+		Use:   "deactivate",
+		Short: "Deactivate the active environment of the current shell",
+		Long:  "Deactivate the active environment of the current shell. This needs the shell integration set up by 'vn shell-init'.",
+		Args:  cobra.NoArgs,
+		RunE:  deactivateCobraFunction,
+	}
+)
+
+func deactivateCobraFunction(cmd *cobra.Command, args []string) error {
+	if !printActivation {
+		return errors.New("Deactivation needs the shell integration. Add 'eval \"$(vn shell-init bash)\"' (or zsh, fish) to the startup file of your shell, or exit the shell started by 'vn activate'.")
+	}
+	if os.Getenv("VIRTUAL_ENV") == "" {
+		return errors.New("No active virtual environment.")
+	}
+	activeShell, err := shell.NewShell()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), activeShell.DeactivationCode())
+	return nil
+}
+
+func init() {
+	deactivateCmd.Flags().BoolVar(&printActivation, "print", false, "print the deactivation code for the current shell")
+}
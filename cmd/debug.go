@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/azr4e1/venv-notary/config"
+	"github.com/azr4e1/venv-notary/shell"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debugCmd = &cobra.Command{
+		Use:   "debug",
+		Short: "Report how vn sees its environment",
+	}
+	debugShellCmd = &cobra.Command{
+		Use:   "shell",
+		Short: "Report which shell is used to activate environments, and how it has been chosen",
+		Long: `Report which shell is used to activate environments, and how it has been chosen.
+
+The shell comes from, in this order: $VN_SHELL or the 'shell' setting, the
+closest supported shell among the parent processes of vn, $SHELL, and the first
+supported shell installed.`,
+		Args: cobra.NoArgs,
+		RunE: debugShellCobraFunction,
+	}
+)
+
+func debugShellCobraFunction(cmd *cobra.Command, args []string) error {
+	d, err := shell.Detect()
+	method := d.Method
+	// the configured shell is exported as $VN_SHELL
+	if _, origin := cfg.Value("shell"); method == shell.MethodOverride && origin == config.OriginConfig {
+		method = "config"
+	}
+	ancestry := []string{}
+	for _, p := range d.Ancestry {
+		ancestry = append(ancestry, fmt.Sprintf("%s[%d]", p.Executable, p.Pid))
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	if err != nil {
+		fmt.Fprintf(w, "shell:\t(none: %v)\n", err)
+	} else {
+		fmt.Fprintf(w, "shell:\t%s (%s)\n", d.Shell.Name(), d.Shell.Executable())
+	}
+	fmt.Fprintf(w, "method:\t%s\n", method)
+	fmt.Fprintf(w, "detail:\t%s\n", d.Detail)
+	fmt.Fprintf(w, "ancestry:\t%s\n", strings.Join(ancestry, " < "))
+	fmt.Fprintf(w, "%s:\t%s\n", shell.ShellEnv, os.Getenv(shell.ShellEnv))
+	fmt.Fprintf(w, "SHELL:\t%s\n", os.Getenv("SHELL"))
+	return w.Flush()
+}
+
+func init() {
+	debugCmd.AddCommand(debugShellCmd)
+}
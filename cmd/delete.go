@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"os"
-
 	venv "github.com/azr4e1/venv-notary"
 	"github.com/azr4e1/venv-notary/graphics"
 	"github.com/spf13/cobra"
@@ -32,11 +30,11 @@ func setupAction(cmd *cobra.Command, args []string) error {
 			return venv.MultipleVersionsError{Message: "Multiple Python versions associated with this environment. Select one Python version."}
 		}
 	} else {
-		currDir, err := os.Getwd()
+		currDir, err := projectDir()
 		if err != nil {
 			return err
 		}
-		vn, err := n.GetLocalVenv(currDir, pythonVersion)
+		vn, err := n.GetLocalVenv(currDir, localVenvName, pythonVersion)
 		if err != nil {
 			return err
 		}
@@ -59,7 +57,11 @@ func deleteAction(cmd *cobra.Command, args []string) func() error {
 				return err
 			}
 		} else {
-			err = notary.DeleteLocal(pythonVersion)
+			dir, err := projectDir()
+			if err != nil {
+				return err
+			}
+			err = notary.DeleteLocal(dir, localVenvName, pythonVersion)
 			if err != nil {
 				return err
 			}
@@ -71,5 +73,7 @@ func deleteAction(cmd *cobra.Command, args []string) func() error {
 func init() {
 	deleteCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "delete a global venv")
 	deleteCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "delete venv with this python version")
+	deleteCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "delete a named local venv")
+	deleteCmd.MarkFlagsMutuallyExclusive("global", "name")
 	deleteCmd.RegisterFlagCompletionFunc("global", venvCompletion)
 }
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envCmd = &cobra.Command{
+		Use:   "env",
+		Short: "Manage the variables set when an environment is active (default local)",
+		Long: `Manage the variables set when an environment is active (default local).
+
+The variables are set by activate, switch, run and exec, and deactivation
+restores the values they replaced. Without --python, set and unset change every
+Python version of the environment.`,
+	}
+	envSetCmd = &cobra.Command{
+		Use:   "set KEY=VALUE...",
+		Short: "Set variables of an environment",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  envSetCobraFunction,
+	}
+	envUnsetCmd = &cobra.Command{
+		Use:   "unset KEY...",
+		Short: "Remove variables of an environment",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  envUnsetCobraFunction,
+	}
+	envListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the variables of an environment",
+		Args:  cobra.NoArgs,
+		RunE:  envListCobraFunction,
+	}
+)
+
+func envSetCobraFunction(cmd *cobra.Command, args []string) error {
+	vars := map[string]string{}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("Invalid assignment '%s'. Use KEY=VALUE.", arg)
+		}
+		vars[key] = value
+	}
+	venvs, err := envVenvs()
+	if err != nil {
+		return err
+	}
+	for _, v := range venvs {
+		err = v.SetEnv(vars)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func envUnsetCobraFunction(cmd *cobra.Command, args []string) error {
+	venvs, err := envVenvs()
+	if err != nil {
+		return err
+	}
+	for _, v := range venvs {
+		err = v.UnsetEnv(args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func envListCobraFunction(cmd *cobra.Command, args []string) error {
+	venvs, err := envVenvs()
+	if err != nil {
+		return err
+	}
+	for i, v := range venvs {
+		vars, err := v.ReadEnv()
+		if err != nil {
+			return err
+		}
+		// versions are told apart only when there are several
+		if len(venvs) > 1 {
+			if i > 0 {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			_, version := venv.ExtractVersion(filepath.Base(v.Path))
+			fmt.Fprintf(cmd.OutOrStdout(), "# %s\n", version)
+		}
+		for _, key := range slices.Sorted(maps.Keys(vars)) {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", key, vars[key])
+		}
+	}
+	return nil
+}
+
+// envVenvs returns the environments selected by the flags of env: one Python
+// version, or all of them.
+func envVenvs() ([]venv.Venv, error) {
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return nil, err
+	}
+	if pythonVersion != "" {
+		var v venv.Venv
+		if globalVenvName != "" {
+			v, err = notary.FindGlobal(globalVenvName, pythonVersion)
+		} else {
+			v, err = resolveLocal(notary)
+		}
+		return []venv.Venv{v}, err
+	}
+	if globalVenvName != "" {
+		return notary.VersionsGlobal(globalVenvName)
+	}
+	currDir, err := projectDir()
+	if err != nil {
+		return nil, err
+	}
+	return notary.VersionsLocal(currDir, localVenvName)
+}
+
+// readEnvFiles returns the variables of the .env files, later files taking
+// precedence.
+func readEnvFiles(paths []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, p := range paths {
+		fileVars, err := venv.ReadEnvFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("Env file '%s' does not exist.", p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(vars, fileVars)
+	}
+	return vars, nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{envSetCmd, envUnsetCmd, envListCmd} {
+		c.Flags().StringVarP(&globalVenvName, "global", "g", "", "use global venv")
+		c.Flags().StringVarP(&pythonVersion, "python", "p", "", "use this python version")
+		c.Flags().StringVarP(&localVenvName, "name", "n", "", "use a named local venv")
+		c.MarkFlagsMutuallyExclusive("global", "name")
+		c.RegisterFlagCompletionFunc("global", venvCompletion)
+		envCmd.AddCommand(c)
+	}
+}
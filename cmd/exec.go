@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	allVersions bool
+	execJobs    int
+	execCmd     = &cobra.Command{
+		Use:   "exec [name] -- command [args...]",
+		Short: "Run a command in several environments and summarize the results",
+		Long: `Run a command in several environments and summarize the results.
+
+With --all-versions, the command runs in every Python version of one
+environment: the global environment name with --global, otherwise the local
+environment of the current directory, or its variant name. Otherwise it runs in
+every local and/or global environment matching --python and --name, like clean.
+
+The output of each environment is printed separately once it is done, followed
+by a table of the results. vn exits with an error if the command failed in any
+environment.`,
+		SilenceUsage: true,
+		RunE:         execCobraFunction,
+	}
+)
+
+// execResult is the outcome of the command in one environment.
+type execResult struct {
+	output   []byte
+	err      error
+	duration time.Duration
+}
+
+func execCobraFunction(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash == -1 || dash == len(args) {
+		return errors.New("Missing command. Separate it from the arguments of vn with '--'.")
+	}
+	names, command := args[:dash], args[dash:]
+	if execJobs < 1 {
+		return errors.New("The number of jobs must be at least 1.")
+	}
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return err
+	}
+	venvs, err := execVenvs(notary, names)
+	if err != nil {
+		return err
+	}
+	if len(venvs) == 0 {
+		return errors.New("No environment matches the filters.")
+	}
+
+	results := make([]execResult, len(venvs))
+	done := make([]chan struct{}, len(venvs))
+	for i := range venvs {
+		done[i] = make(chan struct{})
+	}
+	// the environments are handed out in order, so that a single job runs
+	// them one after the other
+	work := make(chan int)
+	go func() {
+		for i := range venvs {
+			work <- i
+		}
+		close(work)
+	}()
+	for range min(execJobs, len(venvs)) {
+		go func() {
+			for i := range work {
+				start := time.Now()
+				output, err := venvs[i].CombinedOutput(command[0], command[1:]...)
+				results[i] = execResult{output: output, err: err, duration: time.Since(start)}
+				close(done[i])
+			}
+		}()
+	}
+
+	// outputs are printed in order, as soon as the previous ones are
+	failed := 0
+	for i, v := range venvs {
+		<-done[i]
+		name, location, version := execLabel(notary, v)
+		fmt.Fprintf(cmd.OutOrStdout(), "==> %s (%s, %s)\n", name, location, version)
+		cmd.OutOrStdout().Write(results[i].output)
+		if results[i].err != nil {
+			failed++
+			if !errors.As(results[i].err, &venv.ExitError{}) {
+				fmt.Fprintln(cmd.ErrOrStderr(), results[i].err)
+			}
+		}
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout())
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ENVIRONMENT\tLOCATION\tPYTHON\tRESULT\tDURATION")
+	for i, v := range venvs {
+		name, location, version := execLabel(notary, v)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, location, version, execStatus(results[i].err), results[i].duration.Round(time.Millisecond))
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("The command failed in %d of %d environments.", failed, len(venvs))
+	}
+	return nil
+}
+
+// execVenvs returns the environments selected by the flags of exec.
+func execVenvs(notary venv.Notary, names []string) ([]venv.Venv, error) {
+	if len(names) > 1 {
+		return nil, errors.New("Too many environment names.")
+	}
+	name := ""
+	if len(names) == 1 {
+		name = names[0]
+	}
+	if allVersions {
+		if localVenv && globalVenv {
+			return nil, errors.New("Select either --local or --global with --all-versions.")
+		}
+		if globalVenv {
+			if name == "" {
+				return nil, errors.New("Missing name of the global environment.")
+			}
+			return notary.VersionsGlobal(name)
+		}
+		currDir, err := projectDir()
+		if err != nil {
+			return nil, err
+		}
+		return notary.VersionsLocal(currDir, name)
+	}
+
+	if name != "" {
+		return nil, errors.New("An environment name requires --all-versions. Use --name to filter environments.")
+	}
+	if !localVenv && !globalVenv {
+		return nil, errors.New("Select the environments with --local and/or --global, or use --all-versions.")
+	}
+	vPath := []string{}
+	if localVenv {
+		vPath = append(vPath, notary.ListLocal()...)
+	}
+	if globalVenv {
+		vPath = append(vPath, notary.ListGlobal()...)
+	}
+	vPath, err := filterVenvs(notary, vPath, pythonVersion, namePattern)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(vPath)
+	venvs := []venv.Venv{}
+	for _, p := range vPath {
+		venvs = append(venvs, venv.Venv{Path: p})
+	}
+	return venvs, nil
+}
+
+// execLabel returns the name, location and Python version of v as shown by
+// exec.
+func execLabel(notary venv.Notary, v venv.Venv) (string, string, string) {
+	name, version := venv.ExtractVersion(filepath.Base(v.Path))
+	if filepath.Dir(v.Path) == notary.LocalDir() {
+		return venv.RemoveHash(name), "local", version
+	}
+	return name, "global", version
+}
+
+func execStatus(err error) string {
+	var exitErr venv.ExitError
+	switch {
+	case err == nil:
+		return "passed"
+	case errors.As(err, &exitErr):
+		return fmt.Sprintf("failed (%d)", exitErr.Code)
+	default:
+		return "error"
+	}
+}
+
+func init() {
+	execCmd.Flags().BoolVarP(&localVenv, "local", "l", false, "run in local venvs")
+	execCmd.Flags().BoolVarP(&globalVenv, "global", "g", false, "run in global venvs")
+	execCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "run in venvs with this python version")
+	execCmd.Flags().StringVarP(&namePattern, "name", "n", "", "run in venvs with this name pattern")
+	execCmd.Flags().BoolVar(&allVersions, "all-versions", false, "run in every python version of one venv")
+	execCmd.Flags().IntVarP(&execJobs, "jobs", "j", 1, "number of venvs to run the command in at the same time")
+	execCmd.MarkFlagsMutuallyExclusive("all-versions", "python")
+	execCmd.MarkFlagsMutuallyExclusive("all-versions", "name")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	venv "github.com/azr4e1/venv-notary"
+)
+
+// fakeVenv makes a fake environment at path, whose python is a shell script,
+// so that vn registers it.
+func fakeVenv(t *testing.T, path string) {
+	t.Helper()
+	bin := filepath.Join(path, "bin")
+	err := os.MkdirAll(bin, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"activate", "python"} {
+		err = os.WriteFile(filepath.Join(bin, f), []byte("#!/bin/sh\n"), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// execNotary returns a notary with the global environments lib and tool, and
+// the local environments of project, of which docs is a variant.
+func execNotary(t *testing.T) (venv.Notary, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake environments are posix ones")
+	}
+	t.Setenv(venv.HomeEnv, t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	project := t.TempDir()
+	t.Cleanup(func() { projectFlag = "" })
+	notary, err := venv.NewNotary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"lib-py3.11", "lib-py3.12", "tool-py3.12"} {
+		fakeVenv(t, filepath.Join(notary.GlobalDir(), name))
+	}
+	for _, variant := range []string{"", "docs"} {
+		v, err := notary.GetLocalVenv(project, variant, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, version := range []string{"py3.11", "py3.12"} {
+			fakeVenv(t, v.Path+"-"+version)
+		}
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return notary, project
+}
+
+func TestExecVenvs_SelectsTheEnvironments(t *testing.T) {
+	notary, project := execNotary(t)
+	t.Cleanup(func() {
+		allVersions, localVenv, globalVenv, pythonVersion, namePattern = false, false, false, "", ""
+	})
+	projectName := filepath.Base(project)
+	tests := []struct {
+		name        string
+		allVersions bool
+		local       bool
+		global      bool
+		python      string
+		pattern     string
+		args        []string
+		want        []string
+	}{
+		{"all versions of a global venv", true, false, true, "", "", []string{"lib"}, []string{"lib py3.11", "lib py3.12"}},
+		{"all versions of the local venv", true, false, false, "", "", nil, []string{projectName + " py3.11", projectName + " py3.12"}},
+		{"all versions of a local variant", true, true, false, "", "", []string{"docs"}, []string{projectName + "+docs py3.11", projectName + "+docs py3.12"}},
+		{"global venvs of a python", false, false, true, "3.12", "", nil, []string{"lib py3.12", "tool py3.12"}},
+		{"local and global venvs by name", false, true, true, "", "docs|tool", nil, []string{projectName + "+docs py3.11", projectName + "+docs py3.12", "tool py3.12"}},
+		{"local venvs of a python by name", false, true, false, "3.11", "docs", nil, []string{projectName + "+docs py3.11"}},
+	}
+	for _, tt := range tests {
+		allVersions, localVenv, globalVenv, pythonVersion, namePattern = tt.allVersions, tt.local, tt.global, tt.python, tt.pattern
+		projectFlag = project
+		venvs, err := execVenvs(notary, tt.args)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := []string{}
+		for _, v := range venvs {
+			name, _, version := execLabel(notary, v)
+			got = append(got, name+" "+version)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: want %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	// invalid selections
+	for _, tt := range []struct {
+		allVersions, local, global bool
+		args                       []string
+	}{
+		{true, true, true, []string{"lib"}},
+		{true, false, true, nil},
+		{false, true, false, []string{"lib"}},
+		{false, false, false, nil},
+		{false, true, false, []string{"a", "b"}},
+	} {
+		allVersions, localVenv, globalVenv, pythonVersion, namePattern = tt.allVersions, tt.local, tt.global, "", ""
+		if _, err := execVenvs(notary, tt.args); err == nil {
+			t.Errorf("%+v: want an error, got nil", tt)
+		}
+	}
+}
+
+func TestExec_PrintsTheOutputsInOrder(t *testing.T) {
+	notary, _ := execNotary(t)
+	// the first environment is the slowest one
+	script := `case "$VIRTUAL_ENV" in *lib-py3.11) sleep 0.3 ;; esac; echo "in $VIRTUAL_ENV"`
+	output, _, err := executeVn(t, "exec", "-g", "-j", "3", "--", "sh", "-c", script)
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	last := -1
+	for _, name := range []string{"lib-py3.11", "lib-py3.12", "tool-py3.12"} {
+		i := strings.Index(output, "in "+filepath.Join(notary.GlobalDir(), name))
+		if i <= last {
+			t.Errorf("want the output of %s after the previous ones, got\n%s", name, output)
+		}
+		last = i
+	}
+}
+
+func TestExec_FailsWhenTheCommandFailsInAnyEnvironment(t *testing.T) {
+	execNotary(t)
+	script := `case "$VIRTUAL_ENV" in *lib-py3.12) exit 3 ;; esac`
+	output, _, err := executeVn(t, "exec", "-g", "-j", "2", "--", "sh", "-c", script)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 environments") {
+		t.Errorf("want the command to fail in 1 of 3 environments, got %v", err)
+	}
+	for _, w := range []string{"failed (3)", "passed"} {
+		if !strings.Contains(output, w) {
+			t.Errorf("want %q in\n%s", w, output)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/shell"
+	"github.com/spf13/cobra"
+)
+
+// hookEnv holds the environment activated by the hook, so that it can tell it
+// apart from environments activated by hand.
+const hookEnv = "VN_HOOK_ENV"
+
+var (
+	hookCmd = &cobra.Command{
+		Use:   "hook <shell>",
+		Short: "Print the shell hook activating local environments on directory change",
+		Long: `Print the prompt hook for bash, zsh or fish. It activates the environment of the
+current directory when entering a project tree, and deactivates it when leaving,
+like direnv. Environments activated by hand are left alone. Add it to the startup
+file of your shell:
+
+  eval "$(vn hook bash)"    # ~/.bashrc
+  eval "$(vn hook zsh)"     # ~/.zshrc
+  vn hook fish | source     # ~/.config/fish/config.fish`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: shell.InitShells,
+		RunE:      hookCobraFunction,
+	}
+	hookEnvCmd = &cobra.Command{
+		Use:          "hook-env <shell>",
+		Short:        "Print the code updating the environment of the shell for the current directory",
+		Hidden:       true,
+		Args:         cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:    shell.InitShells,
+		SilenceUsage: true,
+		RunE:         hookEnvCobraFunction,
+	}
+)
+
+func hookCobraFunction(cmd *cobra.Command, args []string) error {
+	code, err := shell.Hook(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), code)
+	return nil
+}
+
+// hookEnvCobraFunction runs on every prompt, so it must stay cheap: the
+// environment is looked up on the filesystem and the shell is not probed.
+func hookEnvCobraFunction(cmd *cobra.Command, args []string) error {
+	sh, err := shell.Named(args[0])
+	if err != nil {
+		return err
+	}
+	notaryDir, err := venv.NotaryHome()
+	if err != nil {
+		return err
+	}
+	currDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	target, err := venv.LookupLocal(notaryDir, currDir)
+	if err != nil {
+		return err
+	}
+	active := os.Getenv("VIRTUAL_ENV")
+	hooked := os.Getenv(hookEnv)
+
+	code := []string{}
+	switch {
+	case hooked != "" && active != hooked:
+		// the environment has been changed by hand since the hook activated it
+		code = append(code, sh.UnsetCode(hookEnv))
+	case target == active:
+	case active != "" && hooked == "":
+		// leave environments activated by hand alone
+	case target == "":
+		code = append(code, sh.DeactivationCode(), sh.UnsetCode(hookEnv))
+	default:
+		activation, err := venv.Venv{Path: target}.ShellActivationCode(sh)
+		if err != nil {
+			return err
+		}
+		code = append(code, activation, sh.ExportCode(hookEnv, target))
+	}
+	if len(code) > 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), strings.Join(code, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/config"
+)
+
+func TestHookEnv_IgnoresAnInvalidConfiguration(t *testing.T) {
+	t.Setenv(venv.HomeEnv, t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("VIRTUAL_ENV", "")
+	t.Setenv(hookEnv, "")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	path, err := config.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte("theme = [\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, stderr, err := executeVn(t, "hook-env", "bash")
+	if err != nil || output != "" || stderr != "" {
+		t.Errorf("want no output from the hook, got %q and %q (%v)", output, stderr, err)
+	}
+	_, _, err = executeVn(t, "list")
+	if err == nil || !strings.Contains(err.Error(), "vn config edit") {
+		t.Errorf("want the other commands to report the configuration, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show how a virtual environment has been created (default local)",
+	Args:  cobra.NoArgs,
+	RunE:  infoCobraFunction,
+}
+
+func infoCobraFunction(cmd *cobra.Command, args []string) error {
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return err
+	}
+	var env venv.Venv
+	if globalVenvName != "" {
+		env, err = notary.FindGlobal(globalVenvName, pythonVersion)
+	} else {
+		env, err = resolveLocal(notary)
+	}
+	if err != nil {
+		return err
+	}
+
+	info := notary.Info(env.Path)
+	if jsonOutput {
+		output, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+		return nil
+	}
+
+	name := info.Name
+	if info.Variant != "" {
+		name += venv.VariantSeparator + info.Variant
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "name:\t%s (%s)\n", name, info.Type)
+	fmt.Fprintf(w, "path:\t%s\n", env.Path)
+	if info.Project != "" {
+		fmt.Fprintf(w, "project:\t%s\n", info.Project)
+	}
+	if len(info.LinkedDirs) > 0 {
+		fmt.Fprintf(w, "linked to:\t%s\n", strings.Join(info.LinkedDirs, ", "))
+	}
+	build := notary.Build(env.Path)
+	// environments created by older versions of vn have no metadata
+	if info.CreatedAt == nil {
+		fmt.Fprintf(w, "python:\t%s (%s)\n", build.Label(), build.Implementation)
+		fmt.Fprintf(w, "metadata:\t(none: created by an older version of vn)\n")
+		return w.Flush()
+	}
+	fmt.Fprintf(w, "python:\t%s (%s %s)\n", info.Python, build.Implementation, build.Label())
+	creator := info.Creator
+	if creator == "" {
+		creator = venv.CreatorVenv
+	}
+	fmt.Fprintf(w, "creator:\t%s\n", creator)
+	options := "(none)"
+	if flags := info.Options.Flags(); len(flags) > 0 {
+		options = strings.Join(flags, " ")
+	}
+	fmt.Fprintf(w, "options:\t%s\n", options)
+	fmt.Fprintf(w, "created:\t%s by %s with vn %s\n", info.CreatedAt.Format(time.DateTime), info.CreatedBy, info.VnVersion)
+	return w.Flush()
+}
+
+func init() {
+	infoCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "show a global venv")
+	infoCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "use this python version")
+	infoCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "show a named local venv")
+	infoCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "output in json format")
+	infoCmd.MarkFlagsMutuallyExclusive("global", "name")
+	infoCmd.RegisterFlagCompletionFunc("global", venvCompletion)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	venv "github.com/azr4e1/venv-notary"
+)
+
+func TestInfo_ShowsTheRecordOfTheNotary(t *testing.T) {
+	t.Setenv(venv.HomeEnv, t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	notary, err := venv.NewNotary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobalWith("tool", "", venv.CreateOptions{SystemSitePackages: true, Prompt: "my tool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	err = notary.Link(project, "tool", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := notary.Info(notary.ListGlobal()[0])
+
+	output, _, err := executeVn(t, "info", "-g", "tool", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got venv.VenvInfo
+	err = json.Unmarshal([]byte(output), &got)
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	// the creation time loses its monotonic clock in json
+	if !got.CreatedAt.Equal(*want.CreatedAt) {
+		t.Errorf("want creation time %v, got %v", want.CreatedAt, got.CreatedAt)
+	}
+	got.CreatedAt = want.CreatedAt
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	output, _, err = executeVn(t, "info", "-g", "tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{"tool (global)", "linked to:  " + project, "options:    --system-site-packages --prompt my tool"} {
+		if !strings.Contains(output, w) {
+			t.Errorf("want %q in\n%s", w, output)
+		}
+	}
+}
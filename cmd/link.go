@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/graphics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	linkCmd = &cobra.Command{
+		Use:   "link",
+		Short: "Link the current directory to a global environment",
+		Long:  "Link the current directory to a global environment. Local commands run from the directory, or from any of its subdirectories, use the global environment instead of a local one.",
+		Args:  cobra.NoArgs,
+		RunE:  graphics.StatusMain("Linking directory...", "Directory successfully linked.", linkAction, nil),
+	}
+	unlinkCmd = &cobra.Command{
+		Use:   "unlink",
+		Short: "Remove the link of the current directory to a global environment",
+		Args:  cobra.NoArgs,
+		RunE:  graphics.StatusMain("Unlinking directory...", "Directory successfully unlinked.", unlinkAction, nil),
+	}
+)
+
+func linkAction(cmd *cobra.Command, args []string) func() error {
+	return func() error {
+		notary, err := venv.NewNotary()
+		if err != nil {
+			return err
+		}
+		currDir, err := projectDir()
+		if err != nil {
+			return err
+		}
+		return notary.Link(currDir, globalVenvName, pythonVersion)
+	}
+}
+
+func unlinkAction(cmd *cobra.Command, args []string) func() error {
+	return func() error {
+		notary, err := venv.NewNotary()
+		if err != nil {
+			return err
+		}
+		currDir, err := projectDir()
+		if err != nil {
+			return err
+		}
+		return notary.Unlink(currDir)
+	}
+}
+
+func init() {
+	linkCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "link to this global venv")
+	linkCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "pin this python version")
+	linkCmd.MarkFlagRequired("global")
+	linkCmd.RegisterFlagCompletionFunc("global", venvCompletion)
+}
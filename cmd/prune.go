@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete local environments whose project directory no longer exists",
+		Long: `Delete local environments whose project directory no longer exists.
+
+The project directory of an environment is read from its metadata, so environments created by versions of venv-notary that did not record it are left untouched.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         pruneCobraFunction,
+	}
+)
+
+type prunedVenv struct {
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Project string `json:"project"`
+}
+
+func pruneCobraFunction(cmd *cobra.Command, args []string) error {
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return err
+	}
+	orphans := []prunedVenv{}
+	for _, p := range notary.ListOrphaned() {
+		name, version := venv.ExtractVersion(filepath.Base(p))
+		metadata, _ := notary.Metadata(p)
+		orphans = append(orphans, prunedVenv{
+			Path:    p,
+			Name:    venv.RemoveHash(name),
+			Version: version,
+			Project: metadata.Project,
+		})
+	}
+	stdout := cmd.OutOrStdout()
+	if !jsonOutput {
+		if len(orphans) == 0 {
+			fmt.Fprintln(stdout, "No orphaned environments found.")
+			return nil
+		}
+		for _, o := range orphans {
+			fmt.Fprintf(stdout, "%s (%s)\n", o.Project, o.Version)
+		}
+	}
+	if !dryRun && len(orphans) > 0 && !assumeYes {
+		if jsonOutput {
+			return errors.New("refusing to prompt for confirmation with JSON output. Use --yes or --dry-run.")
+		}
+		ok, err := confirm(fmt.Sprintf("Delete %d environment(s)?", len(orphans)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+	// the environments deleted before a failure are reported too
+	removed := []prunedVenv{}
+	var deleteErr error
+	if dryRun {
+		removed = orphans
+	} else {
+		for _, o := range orphans {
+			deleteErr = notary.Delete(venv.Venv{Path: o.Path})
+			if deleteErr != nil {
+				break
+			}
+			removed = append(removed, o)
+		}
+	}
+	if jsonOutput {
+		output, err := json.MarshalIndent(removed, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(stdout, string(output))
+		return deleteErr
+	}
+	if !dryRun {
+		fmt.Fprintf(stdout, "%d environment(s) deleted.\n", len(removed))
+	}
+	return deleteErr
+}
+
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only show the environments that would be deleted")
+	pruneCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "do not ask for confirmation")
+	pruneCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "output in json format")
+}
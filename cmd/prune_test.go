@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	venv "github.com/azr4e1/venv-notary"
+)
+
+func TestPrune_ReportsTheVenvsDeletedBeforeAFailure(t *testing.T) {
+	t.Setenv(venv.HomeEnv, t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	notary, err := venv.NewNotary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the environments of two removed projects, and of an existing one
+	dir := t.TempDir()
+	paths := map[string]string{}
+	for _, project := range []string{"a", "b", "kept"} {
+		projectDir := filepath.Join(dir, project)
+		v, err := notary.GetLocalVenv(projectDir, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		v.Path += "-py3.12"
+		fakeVenv(t, v.Path)
+		err = v.WriteMetadata(venv.Metadata{Project: projectDir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths[project] = v.Path
+	}
+	err = os.Mkdir(filepath.Join(dir, "kept"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the environment of b cannot be deleted while active
+	t.Setenv("VIRTUAL_ENV", paths["b"])
+
+	output, _, err := executeVn(t, "prune", "--yes", "--json")
+	if err == nil {
+		t.Error("want an error for the active environment, got nil")
+	}
+	removed := []prunedVenv{}
+	err = json.Unmarshal([]byte(output), &removed)
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	if len(removed) != 1 || removed[0].Path != paths["a"] {
+		t.Errorf("want only the environment of a reported, got %+v", removed)
+	}
+	for project, want := range map[string]bool{"a": false, "b": true, "kept": true} {
+		if got := (venv.Venv{Path: paths[project]}).IsVenv(); got != want {
+			t.Errorf("%s: want the environment kept %v, got %v", project, want, got)
+		}
+	}
+
+	t.Setenv("VIRTUAL_ENV", "")
+	output, _, err = executeVn(t, "prune", "--yes", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal([]byte(output), &removed)
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	if len(removed) != 1 || removed[0].Path != paths["b"] {
+		t.Errorf("want the environment of b deleted, got %+v", removed)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pythonsCmd = &cobra.Command{
+		Use:   "pythons",
+		Short: "List the Python interpreters found on the system",
+		Long: `List the Python interpreters found on the system.
+
+Interpreters are looked for in PATH, in /usr/bin, /usr/local/bin and the other
+usual installation prefixes, in the versions of pyenv, and in the interpreters
+directory of the notary. Their versions are cached until they change.`,
+		Args: cobra.NoArgs,
+		RunE: pythonsCobraFunction,
+	}
+)
+
+func pythonsCobraFunction(cmd *cobra.Command, args []string) error {
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return err
+	}
+	interpreters, err := notary.Interpreters()
+	if err != nil {
+		return err
+	}
+	stdout := cmd.OutOrStdout()
+	if jsonOutput {
+		output, err := json.MarshalIndent(interpreters, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, string(output))
+		return nil
+	}
+	if len(interpreters) == 0 {
+		fmt.Fprintln(stdout, "No Python interpreter found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "IMPLEMENTATION\tVERSION\tSOURCE\tPATH")
+	for _, i := range interpreters {
+		// the version shows the flags of free-threaded and debug builds
+		version := strings.TrimPrefix(i.Label(), i.Implementation)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.Implementation, version, i.Source, i.Path)
+	}
+	return w.Flush()
+}
+
+func init() {
+	pythonsCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "output in json format")
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/graphics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	relinkCmd = &cobra.Command{
+		Use:   "relink [old-path]",
+		Short: "Move the local environment of a project to its new location",
+		Long: `Move the local environment of a project that has been moved to the current directory.
+
+Run it from the new location of the project, with the old location as argument. If no argument is given, the orphaned local environments of projects with the same name as the current directory are used as candidates.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: graphics.StatusMain("Relinking environment...", "Environment successfully relinked.", relinkAction, relinkSetup),
+	}
+	relinkSource string
+)
+
+// resolve the old location before starting the status line, so that the
+// candidates are printed in full if the choice is ambiguous.
+func relinkSetup(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		oldDir, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		relinkSource = oldDir
+		return nil
+	}
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return err
+	}
+	currDir, err := projectDir()
+	if err != nil {
+		return err
+	}
+	candidates := notary.RelinkCandidates(currDir)
+	switch len(candidates) {
+	case 0:
+		return errors.New("No orphaned environment found for this directory. Provide the old location of the project.")
+	case 1:
+		relinkSource = candidates[0]
+		return nil
+	default:
+		return fmt.Errorf("Multiple orphaned environments found for this directory. Provide the old location of the project among:\n  %s", strings.Join(candidates, "\n  "))
+	}
+}
+
+func relinkAction(cmd *cobra.Command, args []string) func() error {
+	return func() error {
+		notary, err := venv.NewNotary()
+		if err != nil {
+			return err
+		}
+		currDir, err := projectDir()
+		if err != nil {
+			return err
+		}
+		return notary.RelinkLocal(relinkSource, currDir)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/graphics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameCmd = &cobra.Command{
+		Use:               "rename <old> <new>",
+		Short:             "Rename a global environment",
+		Long:              "Rename a global environment. Every Python version of the environment is renamed, unless one is selected with -p.",
+		Args:              cobra.ExactArgs(2),
+		RunE:              graphics.StatusMain("Renaming environment...", "Environment successfully renamed.", renameAction, nil),
+		ValidArgsFunction: renameCompletion,
+	}
+)
+
+func renameAction(cmd *cobra.Command, args []string) func() error {
+	return func() error {
+		notary, err := venv.NewNotary()
+		if err != nil {
+			return err
+		}
+		return notary.RenameGlobal(args[0], args[1], pythonVersion)
+	}
+}
+
+func renameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return venvCompletion(cmd, args, toComplete)
+}
+
+func init() {
+	renameCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "rename only the venv with this python version")
+}
@@ -1,30 +1,50 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/config"
+	"github.com/azr4e1/venv-notary/graphics"
+	"github.com/azr4e1/venv-notary/shell"
 	"github.com/spf13/cobra"
 )
 
 var (
-	globalVenvName string
-	localVenv      bool
-	globalVenv     bool
-	jsonOutput     bool
-	pythonVersion  string
-	namePattern    string
-	rootCmd        = &cobra.Command{
+	globalVenvName  string
+	localVenvName   string
+	localVenv       bool
+	globalVenv      bool
+	jsonOutput      bool
+	pythonVersion   string
+	creatorFlag     string
+	namePattern     string
+	dryRun          bool
+	assumeYes       bool
+	printActivation bool
+	rootDir         string
+	projectFlag     string
+	cfg             config.Config
+	configErr       error
+	rootCmd         = &cobra.Command{
 		Use:     "vn",
 		Short:   "A wrapper for python-venv",
 		Long:    `venv-notary is an application that makes it easy to manage global and local virtual environments for Python.`,
-		Version: "0.10.1",
+		Version: venv.Version,
+		// flags are parsed, and the configuration loaded, before this runs
+		PersistentPreRunE: applyConfig,
 	}
 )
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr venv.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -32,15 +52,190 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	rootCmd.PersistentFlags().StringVar(&rootDir, "root", "", "use this directory as notary root (default $VN_HOME)")
+	rootCmd.PersistentFlags().StringVarP(&projectFlag, "project", "C", "", "use the local venvs of this directory instead of the current one")
+	rootCmd.MarkPersistentFlagDirname("project")
+
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(activateCmd)
+	rootCmd.AddCommand(switchCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(pythonsCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(cloneCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(relinkCmd)
+	rootCmd.AddCommand(linkCmd)
+	rootCmd.AddCommand(unlinkCmd)
+	rootCmd.AddCommand(deactivateCmd)
+	rootCmd.AddCommand(shellInitCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(hookEnvCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(debugCmd)
+	addCompletionCmds()
 }
 
+// initConfig loads the configuration file and points the notary at the root
+// selected with --root, $VN_HOME or the configuration file, in this order. The
+// root is exported through $VN_HOME, and the shell through $VN_SHELL, so that
+// they are also honoured by the shells and the commands spawned by vn. An
+// invalid configuration file is reported by applyConfig.
 func initConfig() {
+	cfg, configErr = config.Load()
+	if rootDir == "" {
+		rootDir, _ = cfg.Value("root")
+	}
+	if rootDir != "" {
+		root, err := filepath.Abs(rootDir)
+		cobra.CheckErr(err)
+		cobra.CheckErr(os.Setenv(venv.HomeEnv, root))
+	}
+	if shellName, origin := cfg.Value("shell"); origin == config.OriginConfig {
+		cobra.CheckErr(os.Setenv(shell.ShellEnv, shellName))
+	}
+	theme, _ := cfg.Value("theme")
+	cobra.CheckErr(graphics.SetTheme(theme))
+}
+
+// configFlags maps the flags of each command to the configuration key they
+// take their default value from. The other settings apply to several commands
+// and are read where they are used: root, shell and theme by initConfig,
+// python and creator by defaultPython and preferredCreator, auto_create by
+// activate and list.scope by applyConfig.
+func configFlags() map[*cobra.Command]map[string]string {
+	return map[*cobra.Command]map[string]string{
+		listCmd: {"json": "list.json"},
+	}
+}
+
+// applyConfig sets the flags that have not been given on the command line to
+// their configured value. An invalid configuration file stops every command
+// but help, completion and config, which is needed to repair it, and the
+// prompt hook, which leaves the error to the next command instead of printing
+// it at every prompt.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	if configErr != nil {
+		if !ignoresConfig(cmd) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%w. Run 'vn config edit' to fix it.", configErr)
+		}
+		if cmd != hookEnvCmd {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", configErr)
+		}
+	}
+	for flag, key := range configFlags()[cmd] {
+		f := cmd.Flags().Lookup(flag)
+		if f == nil || f.Changed {
+			continue
+		}
+		value, origin := cfg.Value(key)
+		if origin == config.OriginDefault {
+			continue
+		}
+		err := f.Value.Set(value)
+		if err != nil {
+			return fmt.Errorf("invalid value '%s' for flag '--%s' from configuration: %v", value, flag, err)
+		}
+	}
+	if cmd == listCmd && !cmd.Flags().Changed("local") && !cmd.Flags().Changed("global") {
+		switch scope, _ := cfg.Value("list.scope"); scope {
+		case "local":
+			localVenv = true
+		case "global":
+			globalVenv = true
+		}
+	}
+	return nil
+}
+
+// ignoresConfig tells whether cmd works without a valid configuration file.
+func ignoresConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd || c == hookEnvCmd || c.Name() == "help" || c.Name() == "completion" {
+			return true
+		}
+	}
+	return false
+}
+
+// projectDir returns the directory whose local environments are used: the one
+// given with --project, or the current one.
+func projectDir() (string, error) {
+	if projectFlag == "" {
+		return os.Getwd()
+	}
+	dir, err := filepath.Abs(projectFlag)
+	if err != nil {
+		return "", err
+	}
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		return "", fmt.Errorf("Project directory '%s' does not exist.", projectFlag)
+	}
+	return dir, nil
+}
+
+// resolveLocal returns the registered local environment selected by --project,
+// --name and --python.
+func resolveLocal(notary venv.Notary) (venv.Venv, error) {
+	dir, err := projectDir()
+	if err != nil {
+		return venv.Venv{}, err
+	}
+	return notary.ResolveLocal(dir, localVenvName, pythonVersion)
+}
+
+// defaultPython returns the python executable used to create environments when
+// none is given with -p: $VN_PYTHON, then the project file for local
+// environments, then the configuration file.
+func defaultPython(local bool) (string, error) {
+	python, origin := cfg.Value("python")
+	if origin == config.OriginEnv || !local {
+		return python, nil
+	}
+	currDir, err := projectDir()
+	if err != nil {
+		return "", err
+	}
+	project, ok, err := venv.FindProject(currDir)
+	if err != nil {
+		return "", err
+	}
+	if ok && project.Python != "" {
+		return project.Executable(), nil
+	}
+	return python, nil
+}
+
+// preferredCreator returns the creator backend of new environments: the one
+// given with --creator, else the one of VN_CREATOR, else the one of the project
+// file for local environments, else the configured one.
+func preferredCreator(local bool) (string, error) {
+	if creatorFlag != "" {
+		return creatorFlag, nil
+	}
+	creator, origin := cfg.Value("creator")
+	if origin == config.OriginEnv || !local {
+		return creator, nil
+	}
+	currDir, err := projectDir()
+	if err != nil {
+		return "", err
+	}
+	project, ok, err := venv.FindProject(currDir)
+	if err != nil {
+		return "", err
+	}
+	if ok && project.Creator != "" {
+		return project.Creator, nil
+	}
+	return creator, nil
 }
 
 func venvCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
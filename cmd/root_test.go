@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/azr4e1/venv-notary/config"
+	"github.com/spf13/pflag"
+)
+
+func TestPreferredCreator_FollowsThePrecedence(t *testing.T) {
+	project := t.TempDir()
+	err := os.WriteFile(filepath.Join(project, venv.ProjectFile), []byte("creator = \"uv\"\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noProject := t.TempDir()
+	t.Cleanup(func() { creatorFlag, projectFlag, cfg = "", "", config.Config{} })
+
+	tests := []struct {
+		name   string
+		flag   string
+		env    string
+		dir    string
+		local  bool
+		config string
+		want   string
+	}{
+		{"flag", "venv", "virtualenv", project, true, "virtualenv", "venv"},
+		{"VN_CREATOR", "", "virtualenv", project, true, "venv", "virtualenv"},
+		{"project file", "", "", project, true, "virtualenv", "uv"},
+		{"project file of a global venv", "", "", project, false, "virtualenv", "virtualenv"},
+		{"config", "", "", noProject, true, "virtualenv", "virtualenv"},
+		{"default", "", "", noProject, true, "", venv.CreatorVenv},
+	}
+	for _, tt := range tests {
+		t.Setenv("VN_CREATOR", tt.env)
+		creatorFlag, projectFlag, cfg = tt.flag, tt.dir, config.Config{Creator: tt.config}
+		got, err := preferredCreator(tt.local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+// executeVn runs vn with args, from flags reset to their default values, and
+// returns its standard and error outputs.
+func executeVn(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	reset := func(f *pflag.Flag) {
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			s.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	for _, c := range append(rootCmd.Commands(), rootCmd) {
+		c.Flags().VisitAll(reset)
+	}
+	rootDir = ""
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(args)
+	// initConfig keeps the root of the notary
+	defer func() {
+		rootDir = ""
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	}()
+	err := rootCmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"errors"
+
 	venv "github.com/azr4e1/venv-notary"
 	"github.com/spf13/cobra"
 )
 
 var (
-	runCmd = &cobra.Command{
+	envFiles     []string
+	runInProject bool
+	runCmd       = &cobra.Command{
 		Use:   "run",
 		Short: "Run a command in the virtual environment (default local)",
 		RunE:  runCobraFunction,
@@ -23,22 +27,42 @@ func runCobraFunction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	vars, err := readEnvFiles(envFiles)
+	if err != nil {
+		return err
+	}
+	var env venv.Venv
 	if globalVenvName != "" {
-		err = notary.RunGlobal(globalVenvName, pythonVersion, comm, commArgs...)
-		if err != nil {
-			return err
-		}
+		env, err = notary.FindGlobal(globalVenvName, pythonVersion)
 	} else {
-		err = notary.RunLocal(pythonVersion, comm, commArgs...)
+		env, err = resolveLocal(notary)
+	}
+	if err != nil {
+		return err
+	}
+	opts := venv.RunOptions{Env: vars}
+	if runInProject {
+		opts.Dir, err = projectDir()
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	err = env.RunWith(opts, comm, commArgs...)
+	// the command has reported its failure itself, and vn exits with its
+	// status
+	if errors.As(err, &venv.ExitError{}) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	return err
 }
 
 func init() {
 	runCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "run in global venv")
 	runCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "use this python version")
+	runCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "run in a named local venv")
+	runCmd.Flags().StringArrayVar(&envFiles, "env-file", nil, "set the variables of this .env file (can be repeated)")
+	runCmd.Flags().BoolVar(&runInProject, "cd", false, "run the command in the directory given with --project")
+	runCmd.MarkFlagsMutuallyExclusive("global", "name")
 	runCmd.RegisterFlagCompletionFunc("global", venvCompletion)
 }
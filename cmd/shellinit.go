@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/azr4e1/venv-notary/shell"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellInitCmd = &cobra.Command{
+		Use:   "shell-init <shell>",
+		Short: "Print the shell integration, to activate environments in the current shell",
+		Long: `Print the shell integration for bash, zsh or fish. It defines a vn function that
+activates and deactivates environments in the current shell, instead of starting
+a new one. Add it to the startup file of your shell:
+
+  eval "$(vn shell-init bash)"    # ~/.bashrc
+  eval "$(vn shell-init zsh)"     # ~/.zshrc
+  vn shell-init fish | source     # ~/.config/fish/config.fish`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: shell.InitShells,
+		RunE:      shellInitCobraFunction,
+	}
+)
+
+func shellInitCobraFunction(cmd *cobra.Command, args []string) error {
+	code, err := shell.Init(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), code)
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	venv "github.com/azr4e1/venv-notary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkSwitch bool
+	switchCmd   = &cobra.Command{
+		Use:   "switch",
+		Short: "Replace the shell started by activate with one for another environment (default local)",
+		Long: `Replace the shell started by activate with one for another environment (default local).
+
+The shell must be replaced by vn, so run it as 'exec vn switch', or through the
+vn function defined in shells started by activate and by shell-init.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         switchCobraFunction,
+	}
+)
+
+func switchCobraFunction(cmd *cobra.Command, args []string) error {
+	notary, err := venv.NewNotary()
+	if err != nil {
+		return err
+	}
+	if checkSwitch {
+		var env venv.Venv
+		if globalVenvName != "" {
+			env, err = notary.FindGlobal(globalVenvName, pythonVersion)
+		} else {
+			env, err = resolveLocal(notary)
+		}
+		if err != nil {
+			return err
+		}
+		return env.CheckSwitch()
+	}
+	if globalVenvName != "" {
+		return notary.SwitchGlobal(globalVenvName, pythonVersion)
+	}
+	dir, err := projectDir()
+	if err != nil {
+		return err
+	}
+	return notary.SwitchLocal(dir, localVenvName, pythonVersion)
+}
+
+func init() {
+	switchCmd.Flags().StringVarP(&globalVenvName, "global", "g", "", "switch to global venv")
+	switchCmd.Flags().StringVarP(&pythonVersion, "python", "p", "", "use this python version")
+	switchCmd.Flags().StringVarP(&localVenvName, "name", "n", "", "switch to a named local venv")
+	switchCmd.Flags().BoolVar(&checkSwitch, "check", false, "only check that the switch can happen")
+	switchCmd.Flags().MarkHidden("check")
+	switchCmd.MarkFlagsMutuallyExclusive("global", "name")
+	switchCmd.RegisterFlagCompletionFunc("global", venvCompletion)
+}
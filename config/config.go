@@ -0,0 +1,270 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	venv "github.com/azr4e1/venv-notary"
+)
+
+const FileName = "config.toml"
+
+// Origin tells where the value of a setting comes from.
+type Origin string
+
+const (
+	OriginEnv     Origin = "env"
+	OriginConfig  Origin = "config"
+	OriginDefault Origin = "default"
+)
+
+// Setting describes a configuration key.
+type Setting struct {
+	Key     string
+	Env     string
+	Default string
+	Usage   string
+	// allowed values; any value is allowed if empty
+	Values []string
+	isBool bool
+}
+
+var Settings = []Setting{
+	{Key: "root", Env: venv.HomeEnv, Usage: "root directory of the notary"},
+	{Key: "python", Env: "VN_PYTHON", Usage: "python executable used to create environments"},
+	{Key: "creator", Env: "VN_CREATOR", Default: venv.CreatorVenv, Usage: "backend creating environments, replaced by another one if missing", Values: venv.CreatorNames()},
+	{Key: "shell", Env: "VN_SHELL", Usage: "shell used to activate environments"},
+	{Key: "auto_create", Env: "VN_AUTO_CREATE", Default: "true", Usage: "create missing environments on activate", isBool: true},
+	{Key: "theme", Env: "VN_THEME", Default: "default", Usage: "color theme of the interface", Values: []string{"default", "mono"}},
+	{Key: "list.scope", Env: "VN_LIST_SCOPE", Default: "all", Usage: "environments shown by list", Values: []string{"all", "local", "global"}},
+	{Key: "list.json", Env: "VN_LIST_JSON", Default: "false", Usage: "output list in json format", isBool: true},
+}
+
+// Lookup returns the setting with this key.
+func Lookup(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Config is the user configuration of venv-notary.
+type Config struct {
+	Root       string     `toml:"root,omitempty"`
+	Python     string     `toml:"python,omitempty"`
+	Creator    string     `toml:"creator,omitempty"`
+	Shell      string     `toml:"shell,omitempty"`
+	AutoCreate *bool      `toml:"auto_create,omitempty"`
+	Theme      string     `toml:"theme,omitempty"`
+	List       ListConfig `toml:"list,omitempty"`
+}
+
+type ListConfig struct {
+	Scope string `toml:"scope,omitempty"`
+	JSON  *bool  `toml:"json,omitempty"`
+}
+
+// Path returns the path of the user configuration file.
+func Path() (string, error) {
+	configDir, err := venv.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, FileName), nil
+}
+
+// SyntaxError reports a configuration file that cannot be decoded.
+type SyntaxError struct {
+	Message string
+}
+
+func (e SyntaxError) Error() string {
+	return e.Message
+}
+
+// Load reads the user configuration file. A missing file is not an error.
+// Unknown keys and invalid values are reported along with the rest of the
+// configuration, so that it can still be repaired with Set. A file that cannot
+// be decoded is reported as a SyntaxError.
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	meta, err := toml.DecodeFile(path, &config)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, SyntaxError{fmt.Sprintf("invalid configuration file '%s': %v", path, err)}
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return config, fmt.Errorf("unknown configuration key '%s' in '%s'", undecoded[0], path)
+	}
+	for _, s := range Settings {
+		if err := s.validate(config.get(s.Key)); err != nil {
+			return config, fmt.Errorf("%v in '%s'", err, path)
+		}
+	}
+	return config, nil
+}
+
+// Save writes the configuration to the user configuration file.
+func (c Config) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = toml.NewEncoder(file).Encode(c)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Value returns the value of key from the environment, the configuration file
+// or the built-in defaults, in this order.
+func (c Config) Value(key string) (string, Origin) {
+	s, ok := Lookup(key)
+	if !ok {
+		return "", OriginDefault
+	}
+	// invalid values are ignored
+	if value := os.Getenv(s.Env); value != "" && s.validate(value) == nil {
+		return s.normalize(value), OriginEnv
+	}
+	if value := c.get(key); value != "" && s.validate(value) == nil {
+		return s.normalize(value), OriginConfig
+	}
+	return s.Default, OriginDefault
+}
+
+// Bool returns the value of a boolean key.
+func (c Config) Bool(key string) bool {
+	value, _ := c.Value(key)
+	b, _ := strconv.ParseBool(value)
+	return b
+}
+
+// Get returns the value of key in the configuration file.
+func (c Config) Get(key string) (string, error) {
+	if _, ok := Lookup(key); !ok {
+		return "", fmt.Errorf("unknown configuration key '%s'", key)
+	}
+	return c.get(key), nil
+}
+
+// Set sets key to value in the configuration. An empty value unsets the key.
+func (c *Config) Set(key, value string) error {
+	s, ok := Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown configuration key '%s'", key)
+	}
+	err := s.validate(value)
+	if err != nil {
+		return err
+	}
+	var b *bool
+	if s.isBool && value != "" {
+		parsed, _ := strconv.ParseBool(value)
+		b = &parsed
+	}
+	switch key {
+	case "root":
+		c.Root = value
+	case "python":
+		c.Python = value
+	case "creator":
+		c.Creator = value
+	case "shell":
+		c.Shell = value
+	case "auto_create":
+		c.AutoCreate = b
+	case "theme":
+		c.Theme = value
+	case "list.scope":
+		c.List.Scope = value
+	case "list.json":
+		c.List.JSON = b
+	}
+	return nil
+}
+
+func (c Config) get(key string) string {
+	switch key {
+	case "root":
+		return c.Root
+	case "python":
+		return c.Python
+	case "creator":
+		return c.Creator
+	case "shell":
+		return c.Shell
+	case "auto_create":
+		return formatBool(c.AutoCreate)
+	case "theme":
+		return c.Theme
+	case "list.scope":
+		return c.List.Scope
+	case "list.json":
+		return formatBool(c.List.JSON)
+	}
+	return ""
+}
+
+func (s Setting) validate(value string) error {
+	if value == "" {
+		return nil
+	}
+	if s.isBool {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for '%s': expected true or false", value, s.Key)
+		}
+	}
+	if len(s.Values) > 0 && !slices.Contains(s.Values, value) {
+		return fmt.Errorf("invalid value '%s' for '%s': expected one of %s", value, s.Key, strings.Join(s.Values, ", "))
+	}
+	return nil
+}
+
+func (s Setting) normalize(value string) string {
+	switch {
+	case s.isBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return s.Default
+		}
+		return strconv.FormatBool(b)
+	case s.Key == "root":
+		home, err := os.UserHomeDir()
+		if err == nil && (value == "~" || strings.HasPrefix(value, "~/")) {
+			return filepath.Join(home, value[1:])
+		}
+	}
+	return value
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestValue_Precedence(t *testing.T) {
+	var c Config
+	got, origin := c.Value("theme")
+	if got != "default" || origin != OriginDefault {
+		t.Errorf("want built-in default, got '%s' from %s", got, origin)
+	}
+	err := c.Set("theme", "mono")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, origin = c.Value("theme")
+	if got != "mono" || origin != OriginConfig {
+		t.Errorf("want value from config, got '%s' from %s", got, origin)
+	}
+	t.Setenv("VN_THEME", "default")
+	got, origin = c.Value("theme")
+	if got != "default" || origin != OriginEnv {
+		t.Errorf("want value from env, got '%s' from %s", got, origin)
+	}
+	t.Setenv("VN_THEME", "invalid")
+	got, origin = c.Value("theme")
+	if got != "mono" || origin != OriginConfig {
+		t.Errorf("invalid env value should be ignored, got '%s' from %s", got, origin)
+	}
+}
+
+func TestSet_ValidatesValues(t *testing.T) {
+	var c Config
+	testCases := []struct {
+		Key   string
+		Value string
+		Valid bool
+	}{
+		{"auto_create", "false", true},
+		{"auto_create", "maybe", false},
+		{"list.scope", "global", true},
+		{"list.scope", "everything", false},
+		{"python", "python3.12", true},
+		{"creator", "uv", true},
+		{"creator", "conda", false},
+		{"unknown", "value", false},
+	}
+	for _, tc := range testCases {
+		err := c.Set(tc.Key, tc.Value)
+		if tc.Valid && err != nil {
+			t.Errorf("%s = %s: unexpected error: %v", tc.Key, tc.Value, err)
+		}
+		if !tc.Valid && err == nil {
+			t.Errorf("%s = %s: want error, got nil", tc.Key, tc.Value)
+		}
+	}
+	if c.Bool("auto_create") {
+		t.Error("auto_create has not been set to false")
+	}
+}
+
+func TestLoad_ReportsInvalidFiles(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are only used on linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid values are reported with the rest of the configuration, and
+	// ignored
+	err = os.WriteFile(path, []byte("python = \"python3.12\"\ntheme = \"neon\"\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load()
+	if err == nil || errors.As(err, &SyntaxError{}) {
+		t.Errorf("want an invalid value error, got %v", err)
+	}
+	if got, _ := c.Value("python"); got != "python3.12" {
+		t.Errorf("want python3.12, got '%s'", got)
+	}
+	if got, origin := c.Value("theme"); got != "default" || origin != OriginDefault {
+		t.Errorf("want the default theme, got '%s' from %s", got, origin)
+	}
+
+	err = os.WriteFile(path, []byte("theme =\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load()
+	if !errors.As(err, &SyntaxError{}) {
+		t.Errorf("want SyntaxError, got %v", err)
+	}
+
+	os.Remove(path)
+	_, err = Load()
+	if err != nil {
+		t.Errorf("a missing file is not an error, got %v", err)
+	}
+}
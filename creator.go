@@ -0,0 +1,159 @@
+package venv
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Creator backends, in the order they are tried when the preferred one is
+// missing.
+const (
+	CreatorVenv       = "venv"
+	CreatorUv         = "uv"
+	CreatorVirtualenv = "virtualenv"
+)
+
+// Creator creates environments with an external tool.
+type Creator interface {
+	// Name identifies the backend in the configuration and the metadata.
+	Name() string
+	// Available tells whether the tool can create environments for python
+	// with opts.
+	Available(python string, opts CreateOptions) bool
+	// Command returns the command creating an environment at path for
+	// python with opts.
+	Command(python, path string, opts CreateOptions) []string
+}
+
+// Creators returns the creator backends, in the order they are tried.
+func Creators() []Creator {
+	return []Creator{venvCreator{}, uvCreator{}, virtualenvCreator{}}
+}
+
+// CreatorNames returns the names of the creator backends.
+func CreatorNames() []string {
+	names := []string{}
+	for _, c := range Creators() {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// SelectCreator returns the creator backend preferred by opts if it is
+// available for python with opts, and the first available one otherwise. An
+// empty preference selects the first available backend.
+func SelectCreator(python string, opts CreateOptions) (Creator, error) {
+	preferred := opts.Creator
+	creators := Creators()
+	if preferred != "" {
+		i := slices.IndexFunc(creators, func(c Creator) bool { return c.Name() == preferred })
+		if i == -1 {
+			return nil, fmt.Errorf("Unknown creator backend '%s'. Use one of %s.", preferred, strings.Join(CreatorNames(), ", "))
+		}
+		// the preferred backend is tried first, then the others in order
+		others := slices.Concat(creators[:i], creators[i+1:])
+		creators = append([]Creator{creators[i]}, others...)
+	}
+	for _, c := range creators {
+		if c.Available(python, opts) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("No creator backend can create environments for '%s'. Install uv or virtualenv, or the venv and ensurepip modules of this Python.", python)
+}
+
+// venvCreator uses the venv module of the standard library. Some distributions
+// ship it without ensurepip, which venv needs to install pip.
+type venvCreator struct{}
+
+func (venvCreator) Name() string {
+	return CreatorVenv
+}
+
+func (venvCreator) Available(python string, opts CreateOptions) bool {
+	script := "import venv, ensurepip"
+	if opts.WithoutPip {
+		script = "import venv"
+	}
+	// --upgrade-deps needs Python 3.9
+	if opts.UpgradeDeps {
+		script += "; venv.EnvBuilder.upgrade_dependencies"
+	}
+	return exec.Command(python, "-c", script).Run() == nil
+}
+
+func (venvCreator) Command(python, path string, opts CreateOptions) []string {
+	cmdEls := append([]string{python, "-m", "venv"}, opts.Flags()...)
+	return append(cmdEls, path)
+}
+
+// uvCreator uses uv venv, seeding the environment with pip so that it works
+// like the others. uv can neither copy the interpreter nor upgrade the seeded
+// pip.
+type uvCreator struct{}
+
+func (uvCreator) Name() string {
+	return CreatorUv
+}
+
+func (uvCreator) Available(python string, opts CreateOptions) bool {
+	_, err := exec.LookPath("uv")
+	return err == nil && !opts.Copies && !opts.UpgradeDeps
+}
+
+func (uvCreator) Command(python, path string, opts CreateOptions) []string {
+	cmdEls := []string{"uv", "venv", "--quiet", "--python", python}
+	if !opts.WithoutPip {
+		cmdEls = append(cmdEls, "--seed")
+	}
+	if opts.SystemSitePackages {
+		cmdEls = append(cmdEls, "--system-site-packages")
+	}
+	if opts.Prompt != "" {
+		cmdEls = append(cmdEls, "--prompt", opts.Prompt)
+	}
+	return append(cmdEls, path)
+}
+
+// virtualenvCreator uses the virtualenv executable, or the virtualenv module
+// of python if there is no executable.
+type virtualenvCreator struct{}
+
+func (virtualenvCreator) Name() string {
+	return CreatorVirtualenv
+}
+
+func (virtualenvCreator) Available(python string, opts CreateOptions) bool {
+	if _, err := exec.LookPath("virtualenv"); err == nil {
+		return true
+	}
+	return exec.Command(python, "-c", "import virtualenv").Run() == nil
+}
+
+func (virtualenvCreator) Command(python, path string, opts CreateOptions) []string {
+	cmdEls := []string{"virtualenv", "--quiet"}
+	if _, err := exec.LookPath("virtualenv"); err != nil {
+		cmdEls = []string{python, "-m", "virtualenv", "--quiet"}
+	}
+	cmdEls = append(cmdEls, "--python", python)
+	if opts.SystemSitePackages {
+		cmdEls = append(cmdEls, "--system-site-packages")
+	}
+	if opts.WithoutPip {
+		cmdEls = append(cmdEls, "--no-seed")
+	}
+	if opts.Copies {
+		cmdEls = append(cmdEls, "--copies")
+	}
+	// the seed packages are downloaded from PyPI instead of taken from the
+	// wheels embedded in virtualenv
+	if opts.UpgradeDeps {
+		cmdEls = append(cmdEls, "--download")
+	}
+	if opts.Prompt != "" {
+		cmdEls = append(cmdEls, "--prompt", opts.Prompt)
+	}
+	return append(cmdEls, path)
+}
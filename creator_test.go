@@ -0,0 +1,76 @@
+package venv
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSelectCreator_FallsBackWhenThePreferredOneIsMissing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake uv is a shell script")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	err := os.WriteFile(path.Join(bin, "uv"), []byte("#!/bin/sh\n"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the interpreter does not exist, so neither venv nor the virtualenv
+	// module are available
+	python := path.Join(bin, "python3.99")
+
+	for _, preferred := range []string{"", CreatorVenv, CreatorVirtualenv, CreatorUv} {
+		creator, err := SelectCreator(python, CreateOptions{Creator: preferred})
+		if err != nil {
+			t.Fatalf("%q: %v", preferred, err)
+		}
+		if creator.Name() != CreatorUv {
+			t.Errorf("%q: want %s, got %s", preferred, CreatorUv, creator.Name())
+		}
+	}
+	_, err = SelectCreator(python, CreateOptions{Creator: "conda"})
+	if err == nil {
+		t.Error("want an error for an unknown backend, got nil")
+	}
+	for _, opts := range []CreateOptions{{Copies: true}, {UpgradeDeps: true}} {
+		_, err = SelectCreator(python, opts)
+		if err == nil {
+			t.Errorf("%+v: want uv to be skipped, got nil", opts)
+		}
+	}
+	os.Remove(path.Join(bin, "uv"))
+	_, err = SelectCreator(python, CreateOptions{Creator: CreatorUv})
+	if err == nil {
+		t.Error("want an error when no backend is available, got nil")
+	}
+}
+
+func TestCreators_PassTheCreateOptions(t *testing.T) {
+	t.Parallel()
+	opts := CreateOptions{SystemSitePackages: true, WithoutPip: true, Prompt: "gpu"}
+	tests := []struct {
+		creator Creator
+		want    []string
+	}{
+		{venvCreator{}, []string{"--system-site-packages", "--without-pip", "--prompt", "gpu"}},
+		{uvCreator{}, []string{"--system-site-packages", "--prompt", "gpu"}},
+		{virtualenvCreator{}, []string{"--system-site-packages", "--no-seed", "--prompt", "gpu"}},
+	}
+	for _, tt := range tests {
+		cmdEls := tt.creator.Command("python3", "/venvs/lib-py3.12", opts)
+		if cmdEls[len(cmdEls)-1] != "/venvs/lib-py3.12" {
+			t.Errorf("%s: want the path last, got %v", tt.creator.Name(), cmdEls)
+		}
+		args := strings.Join(cmdEls, " ")
+		if !strings.Contains(args, strings.Join(tt.want, " ")) {
+			t.Errorf("%s: want %v in %v", tt.creator.Name(), tt.want, cmdEls)
+		}
+		if tt.creator.Name() == CreatorUv && slices.Contains(cmdEls, "--seed") {
+			t.Errorf("uv: want no pip, got %v", cmdEls)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package venv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// EnvFile stores the variables of an environment, in the format of .env files.
+const EnvFile = "venv-notary.env"
+
+var envKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// plainEnvValue matches the values written without quotes.
+var plainEnvValue = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=-]*$`)
+
+// managedVariables are set by the activation itself.
+var managedVariables = []string{"PATH", "VIRTUAL_ENV", "VIRTUAL_ENV_PROMPT"}
+
+func (v Venv) EnvPath() string {
+	return filepath.Join(v.Path, EnvFile)
+}
+
+// ReadEnv returns the variables set when the environment is active.
+func (v Venv) ReadEnv() (map[string]string, error) {
+	vars, err := ReadEnvFile(v.EnvPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	return vars, err
+}
+
+// SetEnv adds vars to the variables of the environment.
+func (v Venv) SetEnv(vars map[string]string) error {
+	if !v.IsVenv() {
+		return fmt.Errorf("'%s' is not a python environment!", v.Path)
+	}
+	stored, err := v.ReadEnv()
+	if err != nil {
+		return err
+	}
+	for key, value := range vars {
+		if !envKey.MatchString(key) {
+			return fmt.Errorf("Invalid variable name '%s'.", key)
+		}
+		if slices.Contains(managedVariables, key) {
+			return fmt.Errorf("'%s' is set by the activation of the environment, and cannot be changed.", key)
+		}
+		stored[key] = value
+	}
+	return v.writeEnv(stored)
+}
+
+// UnsetEnv removes keys from the variables of the environment.
+func (v Venv) UnsetEnv(keys ...string) error {
+	stored, err := v.ReadEnv()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := stored[key]; !ok {
+			return fmt.Errorf("Variable '%s' is not set in this environment.", key)
+		}
+		delete(stored, key)
+	}
+	return v.writeEnv(stored)
+}
+
+func (v Venv) writeEnv(vars map[string]string) error {
+	if len(vars) == 0 {
+		err := os.Remove(v.EnvPath())
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	content := strings.Builder{}
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		value := vars[key]
+		if !plainEnvValue.MatchString(value) {
+			value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+		}
+		fmt.Fprintf(&content, "%s=%s\n", key, value)
+	}
+	return os.WriteFile(v.EnvPath(), []byte(content.String()), 0o644)
+}
+
+// environ returns the variables of the environment and extra, which take
+// precedence, as KEY=VALUE pairs.
+func (v Venv) environ(extra map[string]string) ([]string, error) {
+	vars, err := v.ReadEnv()
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		vars[key] = value
+	}
+	environ := []string{}
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		environ = append(environ, key+"="+vars[key])
+	}
+	return environ, nil
+}
+
+// ReadEnvFile parses the .env file at path. Lines hold KEY=VALUE assignments,
+// optionally preceded by 'export'. Values may be single-quoted, kept verbatim,
+// or double-quoted, where \n, \t, \" and \\ are escapes. Lines starting with #
+// are comments, and so is the rest of a line after a quoted value or after " #"
+// in an unquoted one.
+// Variables are never expanded.
+func ReadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := parseEnvLine(strings.TrimPrefix(line, "export "))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, number, err)
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// parseEnvLine parses a KEY=VALUE assignment of a .env file.
+func parseEnvLine(assignment string) (string, string, error) {
+	key, value, ok := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !envKey.MatchString(key) {
+		return "", "", fmt.Errorf("Invalid assignment '%s'. Use KEY=VALUE.", assignment)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		if comment := strings.Index(value, " #"); comment != -1 {
+			value = strings.TrimSpace(value[:comment])
+		}
+		return key, value, nil
+	}
+	quote := value[0]
+	end := 1
+	for ; end < len(value) && value[end] != quote; end++ {
+		if quote == '"' && value[end] == '\\' {
+			end++
+		}
+	}
+	if end >= len(value) {
+		return "", "", fmt.Errorf("Unterminated quote in the value of '%s'.", key)
+	}
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", "", fmt.Errorf("Unexpected characters after the value of '%s'.", key)
+	}
+	value = value[1:end]
+	if quote == '"' {
+		value = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value)
+	}
+	return key, value, nil
+}
@@ -0,0 +1,89 @@
+package venv
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestReadEnvFile_ParsesQuotesAndComments(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	content := `# settings
+DJANGO_SETTINGS_MODULE=site.settings
+export TOKEN='a $b "c"'
+MESSAGE="two\nlines" # comment
+PLAIN=value # comment
+EMPTY=
+`
+	file := path.Join(dir, ".env")
+	err := os.WriteFile(file, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars, err := ReadEnvFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"DJANGO_SETTINGS_MODULE": "site.settings",
+		"TOKEN":                  `a $b "c"`,
+		"MESSAGE":                "two\nlines",
+		"PLAIN":                  "value",
+		"EMPTY":                  "",
+	}
+	if len(vars) != len(want) {
+		t.Errorf("want %d variables, got %v", len(want), vars)
+	}
+	for key, value := range want {
+		if vars[key] != value {
+			t.Errorf("want %s=%q, got %q", key, value, vars[key])
+		}
+	}
+
+	err = os.WriteFile(file, []byte("KEY=\"unterminated\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadEnvFile(file)
+	if err == nil {
+		t.Error("should not parse an unterminated quote")
+	}
+}
+
+func TestSetEnv_RoundTrips(t *testing.T) {
+	t.Parallel()
+	venv := Venv{Path: t.TempDir()}
+	err := os.MkdirAll(path.Join(venv.Path, getVenvExecDir()), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"activate", getVenvPythonExec()} {
+		err = os.WriteFile(path.Join(venv.Path, getVenvExecDir(), file), nil, 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = venv.SetEnv(map[string]string{"QUOTED": "say \"hi\"\\now", "PLAIN": "a/b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars, err := venv.ReadEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["QUOTED"] != "say \"hi\"\\now" || vars["PLAIN"] != "a/b" {
+		t.Errorf("unexpected variables %v", vars)
+	}
+	err = venv.SetEnv(map[string]string{"PATH": "/bin"})
+	if err == nil {
+		t.Error("should not set PATH")
+	}
+	err = venv.UnsetEnv("QUOTED", "PLAIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(venv.EnvPath()); !os.IsNotExist(err) {
+		t.Error("env file should be removed once empty")
+	}
+}
@@ -1,5 +1,7 @@
 package venv
 
+import "fmt"
+
 type VenvNotRegisteredError struct {
 	Message string
 }
@@ -15,3 +17,13 @@ type MultipleVersionsError struct {
 func (mve MultipleVersionsError) Error() string {
 	return mve.Message
 }
+
+// ExitError reports that a command run in an environment exited unsuccessfully.
+// Code is its exit status, or 128 plus the number of the signal that killed it.
+type ExitError struct {
+	Code int
+}
+
+func (ee ExitError) Error() string {
+	return fmt.Sprintf("Command exited with status %d.", ee.Code)
+}
@@ -24,7 +24,7 @@ type ListModel struct {
 	notary          vn.Notary
 	showGlobal      bool
 	showLocal       bool
-	pythonVersion   string
+	matchesPython   func(venvPath string) bool
 	environmentType headerType
 	windowWidth     int
 	windowHeight    int
@@ -155,12 +155,12 @@ func (lm *ListModel) Local() {
 
 func (lm *ListModel) Refresh() {
 	err := lm.notary.GetVenvs()
-	if err != nil {
+	if err != nil || lm.error != nil {
 		return
 	}
 	width := min(lm.windowWidth, lm.MaxWidth) - 4 // account for padding
-	localContent := printLocal(lm.notary, width, lm.pythonVersion, lm.itemStyle, lm.currentItemStyle)
-	globalContent := printGlobal(lm.notary, width, lm.pythonVersion, lm.itemStyle, lm.currentItemStyle)
+	localContent := printLocal(lm.notary, width, lm.matchesPython, lm.itemStyle, lm.currentItemStyle)
+	globalContent := printGlobal(lm.notary, width, lm.matchesPython, lm.itemStyle, lm.currentItemStyle)
 	localWidth := lg.Width(localContent)
 	globalWidth := lg.Width(globalContent)
 	localActiveHeader := createActiveHeader(localHeader, localWidth, width, lm.activeTabStyle, lm.tabStyle)
@@ -226,15 +226,12 @@ func newListModel(localVenv, globalVenv bool, pythonExec string) (tea.Model, err
 		environmentType = localHeader
 	}
 
-	var pythonVersion string
-	if pythonExec != "" {
-		pythonVersion, err = vn.PythonVersion(pythonExec)
-	}
+	matchesPython, err := notary.PythonMatcher(pythonExec)
 	lm := ListModel{
 		notary:           notary,
 		showGlobal:       globalVenv,
 		showLocal:        localVenv,
-		pythonVersion:    pythonVersion,
+		matchesPython:    matchesPython,
 		environmentType:  environmentType,
 		activeTabStyle:   activeTab,
 		tabStyle:         tab,
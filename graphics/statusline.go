@@ -2,6 +2,7 @@ package graphics
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -96,7 +97,9 @@ func StatusMain(waitingMessage, exitMessage string, action actionFunc, setup cob
 			}
 		}
 		m := newStatus(waitingMessage, exitMessage, action(cmd, args))
-		p := tea.NewProgram(m)
+		// the status line goes to stderr, so that the output of commands can
+		// be captured
+		p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
 		_, err := p.Run()
 
 		return err
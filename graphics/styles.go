@@ -1,6 +1,8 @@
 package graphics
 
 import (
+	"fmt"
+
 	lg "github.com/charmbracelet/lipgloss"
 )
 
@@ -26,6 +28,24 @@ var (
 	errorStyle        = lg.NewStyle().Italic(true).Foreground(errorColor)
 )
 
+// SetTheme selects the color theme of the interface: "default", or "mono"
+// for no colors at all.
+func SetTheme(name string) error {
+	switch name {
+	case "", "default":
+	case "mono":
+		itemStyle = lg.NewStyle()
+		currentItemStyle = lg.NewStyle().Italic(true).Bold(true)
+		tab = lg.NewStyle().
+			Border(lg.NormalBorder(), false, false, true, false).Faint(true).Padding(0, 1)
+		activeTab = tab.Faint(false).Bold(true)
+		errorStyle = lg.NewStyle().Italic(true)
+	default:
+		return fmt.Errorf("unknown theme '%s'", name)
+	}
+	return nil
+}
+
 // header style
 // var (
 // 	activeTabBorder = lg.Border{
@@ -2,6 +2,7 @@ package graphics
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -19,11 +20,10 @@ const (
 )
 
 const (
-	LocalName             = "Local Environments"
-	GlobalName            = "Global Environments"
-	ReplaceVersion string = "py"
-	truncateRatio         = 0.5
-	truncateChar          = "…"
+	LocalName     = "Local Environments"
+	GlobalName    = "Global Environments"
+	truncateRatio = 0.5
+	truncateChar  = "…"
 )
 
 func fillLine(header string, width int, lineStyle lg.Style) string {
@@ -66,80 +66,135 @@ func createActiveHeader(activeHeader headerType, contentWidth, rowWidth int, act
 	return fillLine(header, contentWidth, inactiveStyle)
 }
 
-func printGlobal(notary vn.Notary, width int, pythonVersion string, itemStyle, currentItemStyle lg.Style) string {
-	items := make(map[string][]string)
-	names := make(map[string]string)
+// listRow is a line of the list: an environment and its versions. A row
+// without versions heads the named environments of a project, listed after it.
+type listRow struct {
+	name     string
+	versions []vn.PythonBuild
+	paths    []string
+}
+
+// add adds the environment at path to the row.
+func (r *listRow) add(notary vn.Notary, path string) {
+	r.versions = append(r.versions, notary.Build(path))
+	r.paths = append(r.paths, path)
+}
+
+func printGlobal(notary vn.Notary, width int, matchesPython func(string) bool, itemStyle, currentItemStyle lg.Style) string {
+	rows := map[string]*listRow{}
 	for _, name := range notary.ListGlobal() {
-		clnName, version := vn.ExtractVersion(filepath.Base(name))
-		if pythonVersion != "" && pythonVersion != version {
+		clnName, _ := vn.ExtractVersion(filepath.Base(name))
+		if !matchesPython(name) {
 			continue
 		}
-		version = strings.ReplaceAll(version, ReplaceVersion, "")
-		oldVersions, ok := items[clnName]
-		if !ok {
-			oldVersions = []string{}
+		if _, ok := rows[clnName]; !ok {
+			rows[clnName] = &listRow{name: clnName}
 		}
-		oldVersions = append(oldVersions, version)
-		items[clnName] = oldVersions
-		names[name] = clnName
+		rows[clnName].add(notary, name)
 	}
 
-	return prettyPrintList(notary, width, names, items, itemStyle, currentItemStyle)
+	return prettyPrintList(notary, width, sortedRows(rows), itemStyle, currentItemStyle)
 }
 
-func printLocal(notary vn.Notary, width int, pythonVersion string, itemStyle, currentItemStyle lg.Style) string {
-	items := make(map[string][]string)
-	names := make(map[string]string)
+func printLocal(notary vn.Notary, width int, matchesPython func(string) bool, itemStyle, currentItemStyle lg.Style) string {
+	// the environments of each project, by variant
+	projects := map[string]map[string]*listRow{}
 	for _, name := range notary.ListLocal() {
-		clnName, version := vn.ExtractVersion(filepath.Base(name))
-		if pythonVersion != "" && pythonVersion != version {
+		clnName, _ := vn.ExtractVersion(filepath.Base(name))
+		if !matchesPython(name) {
 			continue
 		}
-		version = strings.ReplaceAll(version, ReplaceVersion, "")
-		clnNameWithHash := clnName
-		clnName = vn.RemoveHash(clnName)
-		hashVal := clnNameWithHash[len(clnName)+1:]
-		clnName = fmt.Sprintf("%s-%s", clnName, hashVal[:4])
-		oldVersions, ok := items[clnName]
-		if !ok {
-			oldVersions = []string{}
+		clnName, variant := vn.SplitVariant(clnName)
+		if metadata, ok := notary.Metadata(name); ok && metadata.Project != "" {
+			clnName = shortenHome(metadata.Project)
+		} else {
+			clnNameWithHash := clnName
+			clnName = vn.RemoveHash(clnName)
+			hashVal := clnNameWithHash[len(clnName)+1:]
+			clnName = fmt.Sprintf("%s-%s", clnName, hashVal[:4])
+		}
+		if _, ok := projects[clnName]; !ok {
+			projects[clnName] = map[string]*listRow{}
+		}
+		if _, ok := projects[clnName][variant]; !ok {
+			projects[clnName][variant] = &listRow{name: variant}
+		}
+		projects[clnName][variant].add(notary, name)
+	}
+	// a project with named environments is a header, with each environment
+	// under it
+	heads := map[string]*listRow{}
+	nested := map[string][]listRow{}
+	for project, variants := range projects {
+		if len(variants) == 1 && variants[""] != nil {
+			heads[project] = variants[""]
+			heads[project].name = project
+			continue
+		}
+		heads[project] = &listRow{name: project}
+		if def, ok := variants[""]; ok {
+			def.name = "default"
+			nested[project] = append(nested[project], *def)
+			delete(variants, "")
+		}
+		nested[project] = append(nested[project], sortedRows(variants)...)
+	}
+	// directories linked to a global environment show its versions, unless
+	// none matches the python filter
+	for _, l := range notary.ListLinks() {
+		link := listRow{name: fmt.Sprintf("%s → %s", shortenHome(l.Dir), l.Name)}
+		for _, name := range notary.ListGlobal() {
+			clnName, _ := vn.ExtractVersion(filepath.Base(name))
+			if clnName != l.Name || !matchesPython(name) {
+				continue
+			}
+			link.versions = append(link.versions, notary.Build(name))
+		}
+		if len(link.versions) > 0 {
+			heads[link.name] = &link
 		}
-		oldVersions = append(oldVersions, version)
-		items[clnName] = oldVersions
-		names[name] = clnName
 	}
 
-	return prettyPrintList(notary, width, names, items, itemStyle, currentItemStyle)
+	rows := []listRow{}
+	for _, head := range sortedRows(heads) {
+		rows = append(rows, head)
+		for _, r := range nested[head.name] {
+			r.name = "  " + r.name
+			rows = append(rows, r)
+		}
+	}
+	return prettyPrintList(notary, width, rows, itemStyle, currentItemStyle)
 }
 
-func prettyPrintList(notary vn.Notary, width int, nameMap map[string]string, items map[string][]string, itemStyle, currentItemStyle lg.Style) string {
-	activeVenv, _ := notary.GetActiveEnv()
-	activeName := nameMap[activeVenv.Path]
-	_, activeVersion := vn.ExtractVersion(activeVenv.Path)
-	activeVersion = strings.ReplaceAll(activeVersion, ReplaceVersion, "")
-
-	names := []string{}
-	for n := range items {
-		names = append(names, n)
+// sortedRows returns the rows sorted by name.
+func sortedRows(rows map[string]*listRow) []listRow {
+	sorted := []listRow{}
+	for _, r := range rows {
+		sorted = append(sorted, *r)
 	}
+	slices.SortFunc(sorted, func(a, b listRow) int { return vn.AlphanumericSort(a.name, b.name) })
+	return sorted
+}
+
+func prettyPrintList(notary vn.Notary, width int, rows []listRow, itemStyle, currentItemStyle lg.Style) string {
+	activeVenv, _ := notary.GetActiveEnv()
+	active := slices.IndexFunc(rows, func(r listRow) bool { return slices.Contains(r.paths, activeVenv.Path) })
+	activeVersion := notary.Build(activeVenv.Path).Label()
 
 	nameWidth := int(truncateRatio * float64(width))
 	versionWidth := width - nameWidth
-	nameBlock := prettyPrintEnv(names, nameWidth, activeName, itemStyle, currentItemStyle)
-	versionBlock := prettyPrintVersion(names, versionWidth, items, activeName, activeVersion, itemStyle, currentItemStyle)
+	nameBlock := prettyPrintEnv(rows, nameWidth, active, itemStyle, currentItemStyle)
+	versionBlock := prettyPrintVersion(rows, versionWidth, active, activeVersion, itemStyle, currentItemStyle)
 	return lg.JoinHorizontal(lg.Center, nameBlock, versionBlock)
 }
 
-func prettyPrintEnv(names []string, width int, activeName string, itemStyle, currentItemStyle lg.Style) string {
-	slices.SortFunc(names, vn.AlphanumericSort)
-
+func prettyPrintEnv(rows []listRow, width int, active int, itemStyle, currentItemStyle lg.Style) string {
 	coloredNames := []string{}
-	for _, n := range names {
-		fullName := n
+	for i, r := range rows {
 		// check if needs to be truncated
-		n = truncateLine(n, width)
+		n := truncateLine(r.name, width)
 		el := itemStyle.Render(n)
-		if fullName == activeName {
+		if i == active {
 			el = currentItemStyle.Render(n)
 		}
 		coloredNames = append(coloredNames, el)
@@ -149,15 +204,21 @@ func prettyPrintEnv(names []string, width int, activeName string, itemStyle, cur
 	return nameBlock
 }
 
-func prettyPrintVersion(names []string, width int, items map[string][]string, activeName, activeVersion string, itemStyle, currentItemStyle lg.Style) string {
+func prettyPrintVersion(rows []listRow, width int, active int, activeVersion string, itemStyle, currentItemStyle lg.Style) string {
 	versionBlockElements := []string{}
-	for _, name := range names {
-		versions := items[name]
+	for i, r := range rows {
+		// the header of a project has no versions of its own
+		if len(r.versions) == 0 {
+			versionBlockElements = append(versionBlockElements, "")
+			continue
+		}
+		versions := r.versions
 		coloredVersions := []string{}
-		slices.SortFunc(versions, vn.SemanticVersioningSort)
-		for _, v := range versions {
+		slices.SortFunc(versions, vn.CompareBuilds)
+		for _, b := range versions {
+			v := b.Label()
 			el := itemStyle.Render(v)
-			if name == activeName && v == activeVersion {
+			if i == active && v == activeVersion {
 				el = currentItemStyle.Render(v)
 			}
 			coloredVersions = append(coloredVersions, el)
@@ -175,6 +236,21 @@ func prettyPrintVersion(names []string, width int, items map[string][]string, ac
 	return versionBlock
 }
 
+// shortenHome replaces the user's home directory prefix in path with '~'.
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
+
 func truncateLine(line string, width int) string {
 	if width <= 0 {
 		return line
@@ -0,0 +1,100 @@
+package graphics
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	vn "github.com/azr4e1/venv-notary"
+	lg "github.com/charmbracelet/lipgloss"
+)
+
+var hashPrefix = regexp.MustCompile(`-[0-9a-f]{4}\b`)
+
+// fakeVenv makes a fake environment at path, so that the notary registers it.
+func fakeVenv(t *testing.T, path string) {
+	t.Helper()
+	bin := filepath.Join(path, "bin")
+	err := os.MkdirAll(bin, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"activate", "python"} {
+		err = os.WriteFile(filepath.Join(bin, f), nil, 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPrintLocal_GroupsTheVariantsUnderTheirProject(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "")
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	notary, err := vn.NewNotaryAt(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	venvs := map[string][]string{
+		"api": {"", "docs", "test"},
+		"web": {""},
+		"cli": {"docs"},
+	}
+	for project, variants := range venvs {
+		for _, variant := range variants {
+			v, err := notary.GetLocalVenv(filepath.Join(dir, project), variant, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fakeVenv(t, v.Path+"-py3.12")
+			if variant == "" {
+				fakeVenv(t, v.Path+"-py3.11")
+			}
+		}
+	}
+	fakeVenv(t, filepath.Join(notary.GlobalDir(), "tool-py3.11"))
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Link(filepath.Join(dir, "linked"), "tool", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := func(python string) []string {
+		matches, err := notary.PythonMatcher(python)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := []string{}
+		for _, l := range strings.Split(printLocal(notary, 80, matches, lg.NewStyle(), lg.NewStyle()), "\n") {
+			// projects without metadata are shown with the start of their hash
+			l = hashPrefix.ReplaceAllString(l, "")
+			lines = append(lines, strings.Join(strings.Fields(l), " "))
+		}
+		return lines
+	}
+	want := []string{
+		"api",
+		"default (3.11 3.12)",
+		"docs (3.12)",
+		"test (3.12)",
+		"cli",
+		"docs (3.12)",
+		"web (3.11 3.12)",
+		"~/linked → tool (3.11)",
+	}
+	got := lines("")
+	if !slices.Equal(got, want) {
+		t.Errorf("want\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	for _, l := range lines("3.12") {
+		if strings.Contains(l, "linked") {
+			t.Errorf("want the link without matching version skipped, got %s", l)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package venv
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// InterpretersDir holds interpreters managed by the notary, one
+	// installation per directory.
+	InterpretersDir = "interpreters"
+	// InterpretersCache caches the interpreters found on the system.
+	InterpretersCache = "interpreters.json"
+	// interpreterCacheVersion is increased whenever the fields of
+	// cachedInterpreter change, so that older caches are rebuilt.
+	interpreterCacheVersion = 1
+)
+
+// Interpreter sources, from the most to the least preferred.
+const (
+	SourcePath   = "path"
+	SourceSystem = "system"
+	SourcePyenv  = "pyenv"
+	SourceNotary = "notary"
+)
+
+// interpreterName matches the executable names of Python interpreters, such as
+// python3.12, pypy3 or python.exe.
+var interpreterName = regexp.MustCompile(`^(python|pypy|graalpy)[0-9.]*t?(\.exe)?$`)
+
+// Interpreter is a Python interpreter found on the system.
+type Interpreter struct {
+	Path string `json:"path"`
+	PythonBuild
+	// Source tells where the interpreter has been found.
+	Source string `json:"source"`
+}
+
+// cachedInterpreter is an interpreter as stored in the cache, valid as long as
+// its executable is not modified.
+type cachedInterpreter struct {
+	Interpreter
+	ModTime time.Time `json:"mtime"`
+}
+
+// interpreterCache is the content of the cache, by real path of the
+// executables.
+type interpreterCache struct {
+	Version      int                          `json:"version"`
+	Interpreters map[string]cachedInterpreter `json:"interpreters"`
+}
+
+func (n Notary) InterpretersDir() string {
+	return filepath.Join(n.venvDir, InterpretersDir)
+}
+
+// Interpreters returns the Python interpreters found in PATH, in the usual
+// installation prefixes, in the versions of pyenv and in the interpreters
+// directory of the notary. Interpreters reachable from several paths are
+// reported once, with the first path found. Interpreters are run to find their
+// version, unless the cache knows them already.
+func (n Notary) Interpreters() ([]Interpreter, error) {
+	cachePath := ""
+	if cacheDir, err := CacheDir(); err == nil {
+		cachePath = filepath.Join(cacheDir, InterpretersCache)
+	}
+	cache := readInterpreterCache(cachePath)
+	updated := map[string]cachedInterpreter{}
+
+	interpreters := []Interpreter{}
+	for _, candidate := range n.interpreterCandidates() {
+		realPath, err := filepath.EvalSymlinks(candidate.Path)
+		if err != nil {
+			continue
+		}
+		if _, ok := updated[realPath]; ok {
+			continue
+		}
+		stat, err := os.Stat(realPath)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+		cached, ok := cache[realPath]
+		if !ok || !cached.ModTime.Equal(stat.ModTime()) {
+			cached.Interpreter, err = probeInterpreter(realPath)
+			if err != nil {
+				continue
+			}
+			cached.ModTime = stat.ModTime()
+		}
+		updated[realPath] = cached
+		interpreter := cached.Interpreter
+		interpreter.Path, interpreter.Source = candidate.Path, candidate.Source
+		interpreters = append(interpreters, interpreter)
+	}
+	if cachePath != "" {
+		// the cache only saves time: failing to write it is not an error
+		writeInterpreterCache(cachePath, updated)
+	}
+	slices.SortStableFunc(interpreters, func(a, b Interpreter) int {
+		// cpython first, then the highest versions, regular builds first
+		if c := CompareBuilds(PythonBuild{Implementation: a.Implementation}, PythonBuild{Implementation: b.Implementation}); c != 0 {
+			return c
+		}
+		if c := compareVersions(versionParts(b.Version), versionParts(a.Version)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.tagFlags(), b.tagFlags())
+	})
+	return interpreters, nil
+}
+
+// interpreterCandidates returns the executables that may be Python
+// interpreters, in order of preference.
+func (n Notary) interpreterCandidates() []Interpreter {
+	candidates := []Interpreter{}
+	add := func(dir, source string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		names := []string{}
+		for _, e := range entries {
+			if interpreterName.MatchString(e.Name()) {
+				names = append(names, e.Name())
+			}
+		}
+		// the most specific name of an interpreter is reported, e.g. python3.12
+		// rather than python3
+		slices.SortStableFunc(names, func(a, b string) int {
+			return len(b) - len(a)
+		})
+		for _, name := range names {
+			candidates = append(candidates, Interpreter{Path: filepath.Join(dir, name), Source: source})
+		}
+	}
+	// installations hold their executables in bin, or at their root on Windows
+	addInstallations := func(root, source string) {
+		installations, err := os.ReadDir(root)
+		if err != nil {
+			return
+		}
+		for _, i := range installations {
+			add(filepath.Join(root, i.Name(), "bin"), source)
+			add(filepath.Join(root, i.Name()), source)
+		}
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		// shims of pyenv and asdf only forward to the real interpreters, and
+		// fail for versions that are not selected
+		if dir == "" || filepath.Base(dir) == "shims" {
+			continue
+		}
+		add(dir, SourcePath)
+	}
+	if runtime.GOOS != "windows" {
+		for _, dir := range []string{"/usr/bin", "/usr/local/bin", "/opt/homebrew/bin", "/opt/local/bin"} {
+			add(dir, SourceSystem)
+		}
+	}
+	pyenvRoot := os.Getenv("PYENV_ROOT")
+	if home, err := os.UserHomeDir(); pyenvRoot == "" && err == nil {
+		pyenvRoot = filepath.Join(home, ".pyenv")
+	}
+	if pyenvRoot != "" {
+		addInstallations(filepath.Join(pyenvRoot, "versions"), SourcePyenv)
+		addInstallations(filepath.Join(pyenvRoot, "pyenv-win", "versions"), SourcePyenv)
+	}
+	addInstallations(n.InterpretersDir(), SourceNotary)
+	return candidates
+}
+
+// probeInterpreter runs the interpreter at path to find its build.
+func probeInterpreter(path string) (Interpreter, error) {
+	build, err := ProbePython(path)
+	if err != nil {
+		return Interpreter{}, err
+	}
+	return Interpreter{Path: path, PythonBuild: build}, nil
+}
+
+func readInterpreterCache(path string) map[string]cachedInterpreter {
+	if path == "" {
+		return map[string]cachedInterpreter{}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return map[string]cachedInterpreter{}
+	}
+	// a corrupted or outdated cache is rebuilt
+	var cache interpreterCache
+	if json.Unmarshal(content, &cache) != nil || cache.Version != interpreterCacheVersion || cache.Interpreters == nil {
+		return map[string]cachedInterpreter{}
+	}
+	return cache.Interpreters
+}
+
+func writeInterpreterCache(path string, interpreters map[string]cachedInterpreter) error {
+	cache := interpreterCache{Version: interpreterCacheVersion, Interpreters: interpreters}
+	content, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
@@ -0,0 +1,92 @@
+package venv
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpreters_AreCachedUntilModified(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the fake interpreter is a shell script, and the cache follows XDG")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("PYENV_ROOT", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	notary := Notary{venvDir: dir}
+	bin := path.Join(notary.InterpretersDir(), "cpython-3.99", "bin")
+	err := os.MkdirAll(bin, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probes := path.Join(dir, "probes")
+	python := path.Join(bin, "python3.99")
+	script := "#!/bin/sh\necho run >> " + probes + "\nprintf 'cpython\\n3.99.1\\n'\n"
+	err = os.WriteFile(python, []byte(script), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func() Interpreter {
+		interpreters, err := notary.Interpreters()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range interpreters {
+			if i.Path == python {
+				return i
+			}
+		}
+		t.Fatal("interpreter not found")
+		return Interpreter{}
+	}
+	countProbes := func() int {
+		content, _ := os.ReadFile(probes)
+		return strings.Count(string(content), "run")
+	}
+
+	interpreter := find()
+	if interpreter.Version != "3.99.1" || interpreter.Implementation != "cpython" || interpreter.Source != SourceNotary {
+		t.Errorf("unexpected interpreter %+v", interpreter)
+	}
+	find()
+	if n := countProbes(); n != 1 {
+		t.Errorf("want 1 probe, got %d", n)
+	}
+	later := time.Now().Add(time.Minute)
+	err = os.Chtimes(python, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	find()
+	if n := countProbes(); n != 2 {
+		t.Errorf("want the interpreter to be probed again once modified, got %d probes", n)
+	}
+
+	// a cache written by an older version of vn, without abi flags
+	cacheDir, err := CacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(python)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := map[string]any{python: map[string]any{"path": python, "implementation": "cpython", "version": "3.99.1", "source": SourceNotary, "mtime": stat.ModTime()}}
+	content, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(cacheDir, InterpretersCache), content, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	find()
+	if n := countProbes(); n != 3 {
+		t.Errorf("want the interpreter to be probed again with an outdated cache, got %d probes", n)
+	}
+}
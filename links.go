@@ -0,0 +1,139 @@
+package venv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const LinksFile = "links.json"
+
+// Link makes a directory resolve to a global environment instead of a local
+// one.
+type Link struct {
+	Dir  string `json:"dir"`
+	Name string `json:"name"`
+	// Python pins the Python version of the global environment, and may be
+	// empty.
+	Python string `json:"python,omitempty"`
+}
+
+func (n Notary) linksPath() string {
+	return filepath.Join(n.venvDir, LinksFile)
+}
+
+func (n Notary) readLinks() (map[string]Link, error) {
+	links := map[string]Link{}
+	content, err := os.ReadFile(n.linksPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return links, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var linkList []Link
+	err = json.Unmarshal(content, &linkList)
+	if err != nil {
+		return nil, fmt.Errorf("invalid links file '%s': %w", n.linksPath(), err)
+	}
+	for _, l := range linkList {
+		links[l.Dir] = l
+	}
+	return links, nil
+}
+
+func (n Notary) writeLinks() error {
+	content, err := json.MarshalIndent(n.ListLinks(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(n.linksPath(), append(content, '\n'), 0o644)
+}
+
+// ListLinks returns the links of the notary, sorted by directory.
+func (n Notary) ListLinks() []Link {
+	links := []Link{}
+	for _, l := range n.links {
+		links = append(links, l)
+	}
+	slices.SortFunc(links, func(a, b Link) int {
+		return strings.Compare(a.Dir, b.Dir)
+	})
+	return links
+}
+
+// GetLink returns the link of dir, if any.
+func (n Notary) GetLink(dir string) (Link, bool) {
+	l, ok := n.links[dir]
+	return l, ok
+}
+
+// Link makes dir resolve to the registered global environment name. python
+// pins the Python version of the environment, and may be empty.
+func (n *Notary) Link(dir, name, python string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	venv, err := n.GetGlobalVenv(name, python)
+	if err != nil {
+		return err
+	}
+	if python != "" {
+		_, ok, err := n.selectVersion(venv, false, python)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered with this Python version.", name)}
+		}
+	} else if !n.IsRegisteredNoVersion(venv, false) {
+		return VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered.", name)}
+	}
+	localVenv, err := n.GetLocalVenv(dir, "", "")
+	if err != nil {
+		return err
+	}
+	if n.IsRegisteredNoVersion(localVenv, true) {
+		return errors.New("A local environment is already registered for this directory. Delete it before linking the directory.")
+	}
+	n.links[dir] = Link{Dir: dir, Name: NormalizeName(name), Python: python}
+	return n.writeLinks()
+}
+
+// Unlink removes the link of dir.
+func (n *Notary) Unlink(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if _, ok := n.links[dir]; !ok {
+		return fmt.Errorf("'%s' is not linked to a global environment.", dir)
+	}
+	delete(n.links, dir)
+	return n.writeLinks()
+}
+
+// findLink returns the global environment dir is linked to. The second return
+// value is false if dir has no link.
+func (n Notary) findLink(dir, python string) (Venv, bool, error) {
+	l, ok := n.links[dir]
+	if !ok {
+		return Venv{}, false, nil
+	}
+	if python == "" {
+		python = l.Python
+	}
+	venv, err := n.FindGlobal(l.Name, python)
+	// a dangling link must not look like a missing local environment, which
+	// would be created on activation
+	if errors.As(err, &VenvNotRegisteredError{}) {
+		return Venv{}, true, fmt.Errorf("'%s' is linked to global environment '%s', which is not registered with this Python version.", dir, l.Name)
+	}
+	return venv, true, err
+}
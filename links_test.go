@@ -0,0 +1,152 @@
+package venv
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLink_ResolvesToTheGlobalVenv(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectDir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subDir := path.Join(projectDir, "src")
+	err = os.Mkdir(subDir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Link(projectDir, "shared", "")
+	if err == nil {
+		t.Error("should not link to an unregistered environment")
+	}
+	err = notary.CreateGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Link(projectDir, "shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	global, err := notary.FindGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	venv, err := notary.FindLocal(subDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if venv.Path != global.Path {
+		t.Errorf("want %s, got %s", global.Path, venv.Path)
+	}
+	err = notary.RenameGlobal("shared", "common", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := notary.GetLink(projectDir); l.Name != "common" {
+		t.Errorf("link has not followed the rename: '%s'", l.Name)
+	}
+	err = notary.Unlink(projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = notary.FindLocal(subDir, "", "")
+	if !errors.As(err, &VenvNotRegisteredError{}) {
+		t.Errorf("unlinked directory still resolves: %v", err)
+	}
+}
+
+func TestDeleteGlobal_RemovesTheLinksToTheVenv(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	projectDir := t.TempDir()
+	notary := Notary{venvDir: dir}
+	err := notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Link(projectDir, "shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	global, err := notary.FindGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a local environment left behind, e.g. by relink: the link wins
+	local, err := notary.GetLocalVenv(projectDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local = withVersion(local, "py3.12")
+	err = os.MkdirAll(path.Join(local.Path, "bin"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"bin/activate", "bin/python"} {
+		err = os.WriteFile(path.Join(local.Path, f), nil, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	venv, err := notary.FindLocal(projectDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if venv.Path != global.Path {
+		t.Errorf("want the linked %s, got %s", global.Path, venv.Path)
+	}
+	if got, _ := LookupLocal(dir, projectDir); got != global.Path {
+		t.Errorf("want the linked %s from LookupLocal, got %s", global.Path, got)
+	}
+
+	err = notary.DeleteGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, ok := notary.GetLink(projectDir); ok {
+		t.Errorf("link to the deleted environment is left: %+v", l)
+	}
+	venv, err = notary.FindLocal(projectDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if venv.Path != local.Path {
+		t.Errorf("want the local %s, got %s", local.Path, venv.Path)
+	}
+}
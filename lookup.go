@@ -0,0 +1,81 @@
+package venv
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LookupLocal returns the path of the environment that local commands run from
+// dir would use: the global environment dir or one of its parents is linked
+// to, or their local environment. It is meant to run on every prompt, so
+// it only looks at the filesystem: the notary is not loaded and Python is never
+// run. When several Python versions are registered, the one pinned by the
+// project file is preferred, else the highest. The path is empty if there is
+// no environment.
+func LookupLocal(notaryDir, dir string) (string, error) {
+	n := Notary{venvDir: notaryDir}
+	links, err := n.readLinks()
+	if err != nil {
+		return "", err
+	}
+	for dir != filepath.Dir(dir) {
+		if l, ok := links[dir]; ok {
+			return lookupVersions(n.GlobalDir(), l.Name, pinnedVersion(l.Python)), nil
+		}
+		name, err := createLocalName(dir, "")
+		if err != nil {
+			dir = filepath.Dir(dir)
+			continue
+		}
+		preferred := ""
+		if project, err := ReadProject(filepath.Join(dir, ProjectFile)); err == nil {
+			preferred = pinnedVersion(project.Python)
+		}
+		if venv := lookupVersions(n.LocalDir(), name, preferred); venv != "" {
+			return venv, nil
+		}
+		dir = filepath.Dir(dir)
+	}
+	return "", nil
+}
+
+// lookupVersions returns the environment of parent named name with the
+// preferred version, or with the highest one, regular CPython builds first.
+func lookupVersions(parent, name, preferred string) string {
+	matches, err := filepath.Glob(filepath.Join(parent, name+"-*"))
+	if err != nil {
+		return ""
+	}
+	versions := map[string]string{}
+	tags := []string{}
+	for _, m := range matches {
+		clnName, version := ExtractVersion(filepath.Base(m))
+		if clnName != name || version == "" || !(Venv{Path: m}).IsVenv() {
+			continue
+		}
+		versions[version] = m
+		tags = append(tags, version)
+	}
+	if venv, ok := versions[preferred]; ok {
+		return venv
+	}
+	return versions[preferredVersionTag(tags)]
+}
+
+// pinnedVersion returns the version suffix of the environments created with
+// python, if it can be told without running it: "3.12", "py3.12" and
+// "python3.12" all give "py3.12", "3.13t" gives "py3.13t" and "pypy3.10"
+// gives "pypy3.10".
+func pinnedVersion(python string) string {
+	tag := filepath.Base(python)
+	if rest, ok := strings.CutPrefix(tag, "python"); ok {
+		tag = VersionPrefix + rest
+	} else if tag != "" && tag[0] >= '0' && tag[0] <= '9' {
+		tag = VersionPrefix + tag
+	}
+	build, ok := ParseVersionTag(tag)
+	if !ok {
+		return ""
+	}
+	return build.Tag()
+}
@@ -0,0 +1,80 @@
+package venv
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLookupLocal_FindsTheVenvWithoutLoadingTheNotary(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: path.Join(dir, "notary")}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectDir := path.Join(dir, "project")
+	linkedDir := path.Join(dir, "linked")
+	err = os.MkdirAll(path.Join(projectDir, "src"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(path.Join(linkedDir, "src"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := notary.GetLocalVenv(projectDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = addVersion(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Link(linkedDir, "shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	global, err := notary.FindGlobal("shared", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LookupLocal(notary.Dir(), path.Join(projectDir, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != v.Path {
+		t.Errorf("want %s, got %s", v.Path, got)
+	}
+	got, err = LookupLocal(notary.Dir(), path.Join(linkedDir, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != global.Path {
+		t.Errorf("want %s, got %s", global.Path, got)
+	}
+	got, err = LookupLocal(notary.Dir(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Errorf("want no environment, got %s", got)
+	}
+}
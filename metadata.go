@@ -0,0 +1,111 @@
+package venv
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+const MetadataFile = "venv-notary.json"
+
+// Metadata is the registry record stored alongside every environment created
+// by the notary.
+type Metadata struct {
+	Project string `json:"project,omitempty"`
+	// Python is the base interpreter the environment has been created with.
+	Python string `json:"python"`
+	// Version is the full version of the interpreter, e.g. 3.12.4.
+	Version string `json:"version"`
+	// Implementation and ABIFlags are empty for environments created by
+	// older versions, which only supported CPython.
+	Implementation string `json:"implementation,omitempty"`
+	ABIFlags       string `json:"abi_flags,omitempty"`
+	// Creator is the backend that created the environment, empty for
+	// environments created by older versions with venv.
+	Creator string `json:"creator,omitempty"`
+	// Options are the options the environment has been created with.
+	Options   CreateOptions `json:"options"`
+	CreatedAt time.Time     `json:"created_at"`
+	CreatedBy string        `json:"created_by"`
+	VnVersion string        `json:"vn_version"`
+}
+
+func newMetadata(venv Venv, project string) (Metadata, error) {
+	executable := venv.Python
+	if executable == "" {
+		executable = getVenvPythonExec()
+	}
+	pythonPath, err := exec.LookPath(executable)
+	if err != nil {
+		return Metadata{}, err
+	}
+	pythonPath, err = filepath.Abs(pythonPath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	build, base, err := probePython(pythonPath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	// record the interpreter itself rather than the shim that runs it
+	if base != "" {
+		pythonPath = base
+	}
+	metadata := Metadata{
+		Python:         pythonPath,
+		Version:        build.Version,
+		Implementation: build.Implementation,
+		ABIFlags:       build.ABIFlags,
+	}
+	return metadata.stamp(project), nil
+}
+
+// stamp sets the project and the creation details of the record to the
+// current ones.
+func (m Metadata) stamp(project string) Metadata {
+	m.Project = project
+	m.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	m.CreatedBy = ""
+	if u, err := user.Current(); err == nil {
+		m.CreatedBy = u.Username
+	}
+	m.VnVersion = Version
+	return m
+}
+
+// Build returns the build of the interpreter recorded in the metadata.
+func (m Metadata) Build() PythonBuild {
+	build := PythonBuild{Implementation: m.Implementation, Version: m.Version, ABIFlags: m.ABIFlags}
+	if build.Implementation == "" {
+		build.Implementation = CPython
+	}
+	return build
+}
+
+func (v Venv) MetadataPath() string {
+	return filepath.Join(v.Path, MetadataFile)
+}
+
+func (v Venv) ReadMetadata() (Metadata, error) {
+	content, err := os.ReadFile(v.MetadataPath())
+	if err != nil {
+		return Metadata{}, err
+	}
+	var metadata Metadata
+	err = json.Unmarshal(content, &metadata)
+	if err != nil {
+		return Metadata{}, err
+	}
+	return metadata, nil
+}
+
+func (v Venv) WriteMetadata(metadata Metadata) error {
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(v.MetadataPath(), append(content, '\n'), 0o644)
+}
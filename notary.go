@@ -1,17 +1,23 @@
 package venv
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 )
 
 type Notary struct {
 	venvDir  string
 	venvList map[string]Location
+	metadata map[string]Metadata
+	links    map[string]Link
 }
 
 type Location string
@@ -24,18 +30,27 @@ const (
 const (
 	NotaryDir     = "venv-notary"
 	VersionPrefix = "py"
+	Version       = "0.10.1"
+	// HomeEnv is the environment variable overriding the notary root.
+	HomeEnv = "VN_HOME"
 )
 
+// NewNotary returns the notary rooted at NotaryHome.
 func NewNotary() (Notary, error) {
-	dataHome, err := getDataHome()
+	notaryDir, err := NotaryHome()
 	if err != nil {
 		return Notary{}, err
 	}
-	notaryDir := filepath.Join(dataHome, NotaryDir)
+	return NewNotaryAt(notaryDir)
+}
+
+// NewNotaryAt returns the notary rooted at notaryDir, creating its directories
+// if needed.
+func NewNotaryAt(notaryDir string) (Notary, error) {
 	notary := Notary{
 		venvDir: notaryDir,
 	}
-	err = notary.SetUp()
+	err := notary.SetUp()
 	if err != nil {
 		return Notary{}, err
 	}
@@ -60,6 +75,11 @@ func (n Notary) SetUp() error {
 	return nil
 }
 
+// Dir returns the root directory of the notary.
+func (n Notary) Dir() string {
+	return n.venvDir
+}
+
 func (n Notary) GlobalDir() string {
 	return filepath.Join(n.venvDir, string(GlobalLoc))
 }
@@ -78,6 +98,7 @@ func (n *Notary) GetVenvs() error {
 		return err
 	}
 	venvList := map[string]Location{}
+	metadata := map[string]Metadata{}
 
 	// add global venvs
 	for _, g := range globDirs {
@@ -87,6 +108,10 @@ func (n *Notary) GetVenvs() error {
 			continue
 		}
 		venvList[v.Path] = GlobalLoc
+		// environments created by older versions have no metadata
+		if m, err := v.ReadMetadata(); err == nil {
+			metadata[v.Path] = m
+		}
 	}
 	// add local venvs
 	for _, g := range localDirs {
@@ -95,23 +120,95 @@ func (n *Notary) GetVenvs() error {
 			continue
 		}
 		venvList[v.Path] = LocalLoc
+		if m, err := v.ReadMetadata(); err == nil {
+			metadata[v.Path] = m
+		}
+	}
+	links, err := n.readLinks()
+	if err != nil {
+		return err
 	}
 	n.venvList = venvList
+	n.metadata = metadata
+	n.links = links
 	return nil
 }
 
-func (n *Notary) CreateLocal(python string) error {
-	currDir, err := os.Getwd()
+func (n *Notary) register(venv Venv, loc Location, metadata Metadata) error {
+	n.venvList[venv.Path] = loc
+	err := venv.WriteMetadata(metadata)
 	if err != nil {
 		return err
 	}
-	venvName, err := createLocalName(currDir)
+	n.metadata[venv.Path] = metadata
+	return nil
+}
+
+// Metadata returns the registry record of the environment at path, if any.
+func (n Notary) Metadata(path string) (Metadata, bool) {
+	m, ok := n.metadata[path]
+	return m, ok
+}
+
+// Build returns the build of the interpreter of the registered environment at
+// path, as recorded in its metadata or, for environments without metadata, in
+// its name, which lacks the patch level.
+func (n Notary) Build(path string) PythonBuild {
+	if m, ok := n.metadata[path]; ok && m.Version != "" {
+		return m.Build()
+	}
+	_, tag := ExtractVersion(filepath.Base(path))
+	build, _ := ParseVersionTag(tag)
+	return build
+}
+
+// CreateLocal creates the local environment of dir. name selects one of
+// several environments of the directory, and may be empty. If the directory
+// belongs to a project with a project file, the environment is created for the
+// project directory instead, with the Python and the prompt of the project file.
+func (n *Notary) CreateLocal(dir, name, python string) error {
+	return n.CreateLocalWith(dir, name, python, CreateOptions{})
+}
+
+// CreateLocalWith creates the local environment of dir like CreateLocal, with
+// opts.
+func (n *Notary) CreateLocalWith(dir, name, python string, opts CreateOptions) error {
+	currDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	project, hasProject, err := FindProject(currDir)
+	if err != nil {
+		return err
+	}
+	if hasProject {
+		currDir = project.Dir
+		if python == "" {
+			python = project.Executable()
+		}
+	}
+	python, err = n.ResolvePython(python)
+	if err != nil {
+		return err
+	}
+	if l, ok := n.links[currDir]; ok && name == "" {
+		return fmt.Errorf("Directory is linked to global environment '%s'. Unlink it before creating a local environment.", l.Name)
+	}
+	venvName, err := createLocalName(currDir, name)
 	if err != nil {
 		return err
 	}
+	prompt := RemoveHash(venvName)
+	if project.Name != "" {
+		_, variant := SplitVariant(venvName)
+		prompt = project.Name
+		if variant != "" {
+			prompt += VariantSeparator + variant
+		}
+	}
 	// change to empty dir so there is no clash with python script for venv
 	err = SafeDir(func() error {
-		venv := Venv{Path: filepath.Join(n.LocalDir(), venvName), Name: RemoveHash(venvName), Python: python}
+		venv := Venv{Path: filepath.Join(n.LocalDir(), venvName), Name: prompt, Python: python}
 		venv, err = addVersion(venv)
 		if err != nil {
 			return err
@@ -120,23 +217,41 @@ func (n *Notary) CreateLocal(python string) error {
 		if ok {
 			return errors.New("Environment already exists at this location and with this Python version.")
 		}
-		err = venv.Create()
+		metadata, err := newMetadata(venv, currDir)
 		if err != nil {
 			return err
 		}
-		n.venvList[venv.Path] = LocalLoc
-		return nil
+		creator, err := venv.create(opts)
+		if err != nil {
+			return err
+		}
+		metadata.Creator = creator.Name()
+		metadata.Options = opts
+		err = n.register(venv, LocalLoc, metadata)
+		if err != nil {
+			return err
+		}
+		return venv.InstallRequirements(project.RequirementFiles()...)
 	})
 	return err
 }
 
 func (n *Notary) CreateGlobal(name, python string) error {
+	return n.CreateGlobalWith(name, python, CreateOptions{})
+}
+
+// CreateGlobalWith creates the global environment name like CreateGlobal, with
+// opts.
+func (n *Notary) CreateGlobalWith(name, python string, opts CreateOptions) error {
 	name = NormalizeName(name)
 	if name == "" {
 		return errors.New("Invalid venv name. Please use a name that contains only letters, digits, '_' and '-'.")
 	}
+	python, err := n.ResolvePython(python)
+	if err != nil {
+		return err
+	}
 	// change to empty dir so there is no clash with python script for venv
-	var err error
 	err = SafeDir(func() error {
 		venv := Venv{Path: filepath.Join(n.GlobalDir(), name), Python: python, Name: name}
 		venv, err = addVersion(venv)
@@ -147,76 +262,271 @@ func (n *Notary) CreateGlobal(name, python string) error {
 		if ok {
 			return errors.New("Environment already exists with this name and this Python version.")
 		}
-		err = venv.Create()
+		metadata, err := newMetadata(venv, "")
 		if err != nil {
 			return err
 		}
-		n.venvList[venv.Path] = GlobalLoc
-		return nil
+		creator, err := venv.create(opts)
+		if err != nil {
+			return err
+		}
+		metadata.Creator = creator.Name()
+		metadata.Options = opts
+		return n.register(venv, GlobalLoc, metadata)
 
 	})
 	return err
 }
 
-func (n *Notary) delete(venv Venv) error {
+// Delete deletes the registered environment venv. The links to a global
+// environment are removed along with its last Python version.
+func (n *Notary) Delete(venv Venv) error {
+	loc := n.venvList[venv.Path]
 	err := venv.Delete()
 	if err != nil {
 		return err
 	}
 	delete(n.venvList, venv.Path)
-	return nil
+	delete(n.metadata, venv.Path)
+	if loc != GlobalLoc {
+		return nil
+	}
+	name, _ := ExtractVersion(filepath.Base(venv.Path))
+	global, err := n.GetGlobalVenv(name, "")
+	if err != nil || n.IsRegisteredNoVersion(global, false) {
+		return err
+	}
+	unlinked := false
+	for dir, l := range n.links {
+		if l.Name == name {
+			delete(n.links, dir)
+			unlinked = true
+		}
+	}
+	if !unlinked {
+		return nil
+	}
+	return n.writeLinks()
 }
 
-func (n *Notary) DeleteLocal(python string) error {
-	currDir, err := os.Getwd()
+// DeleteLocal deletes the local environment of dir.
+func (n *Notary) DeleteLocal(dir, name, python string) error {
+	currDir, err := filepath.Abs(dir)
 	if err != nil {
 		return err
 	}
-	venv, err := n.GetLocalVenv(currDir, python)
+	venv, err := n.GetLocalVenv(currDir, name, python)
 	if err != nil {
 		return err
 	}
-	if venvs := n.GetRegisteredVersionsOfVenv(venv, true); python == "" && len(venvs) >= 1 {
-		if len(venvs) == 1 {
-			registeredVenv := Venv{Path: venvs[0]}
-			return n.delete(registeredVenv)
-		}
-		return MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
-	}
-	venv, err = addVersion(venv)
+	venv, ok, err := n.selectVersion(venv, true, python)
 	if err != nil {
 		return err
 	}
-	_, ok := n.venvList[venv.Path]
-	if ok {
-		return n.delete(venv)
-	} else {
-		return errors.New("No environment is registered for current directory with this Python version.")
+	if !ok {
+		return errors.New("No environment is registered for this directory with this Python version.")
 	}
+	return n.Delete(venv)
 }
 
 func (n *Notary) DeleteGlobal(name, python string) error {
-	venv, err := n.GetGlobalVenv(name, python)
+	venv, err := n.FindGlobal(name, python)
+	if err != nil {
+		return err
+	}
+	return n.Delete(venv)
+}
+
+// RenameGlobal renames the global environment old to new. If python is empty,
+// every registered Python version of the environment is renamed.
+func (n *Notary) RenameGlobal(old, new, python string) error {
+	venv, err := n.GetGlobalVenv(old, python)
+	if err != nil {
+		return err
+	}
+	newVenv, err := n.GetGlobalVenv(new, python)
+	if err != nil {
+		return err
+	}
+	venvs, err := n.GetMatchingVersionsOfVenv(venv, false, python)
 	if err != nil {
 		return err
 	}
-	if venvs := n.GetRegisteredVersionsOfVenv(venv, false); python == "" && len(venvs) >= 1 {
-		if len(venvs) == 1 {
-			registeredVenv := Venv{Path: venvs[0]}
-			return n.delete(registeredVenv)
+	if len(venvs) == 0 {
+		return VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered with this Python version.", old)}
+	}
+	// check every variant before moving any of them
+	slices.Sort(venvs)
+	moves := map[string]Venv{}
+	for _, v := range venvs {
+		if (Venv{Path: v}).IsActive() {
+			return errors.New("environment is active. Deactivate it before renaming it.")
+		}
+		_, version := ExtractVersion(v)
+		dst := n.keepPrompt(v, withVersion(newVenv, version))
+		if n.IsRegistered(dst) {
+			return fmt.Errorf("Environment '%s' already exists with Python version %s.", newVenv.Name, version)
 		}
-		return MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
+		if _, err := os.Stat(dst.Path); !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Directory or file already exists at '%s'.", dst.Path)
+		}
+		moves[v] = dst
+	}
+	// versions already moved are moved back if one fails, so that the
+	// environment is not left split across two names
+	moved := []Venv{}
+	for _, v := range venvs {
+		src := Venv{Path: v, Name: Venv{Path: v}.prompt()}
+		err = src.Move(moves[v])
+		if err != nil {
+			for _, back := range slices.Backward(moved) {
+				moves[back.Path].Move(back)
+			}
+			return err
+		}
+		moved = append(moved, src)
+	}
+	for v, dst := range moves {
+		n.venvList[dst.Path] = GlobalLoc
+		delete(n.venvList, v)
+		if m, ok := n.metadata[v]; ok {
+			n.metadata[dst.Path] = m
+			delete(n.metadata, v)
+		}
+	}
+	// links follow the environment once no version is left behind
+	oldVenv, _ := n.GetGlobalVenv(old, "")
+	if n.IsRegisteredNoVersion(oldVenv, false) {
+		return nil
+	}
+	relinked := false
+	for dir, l := range n.links {
+		if l.Name == oldVenv.Name {
+			l.Name = newVenv.Name
+			n.links[dir] = l
+			relinked = true
+		}
+	}
+	if relinked {
+		return n.writeLinks()
+	}
+	return nil
+}
+
+// keepPrompt returns dst, the destination of the environment at src once
+// moved or cloned, without name if src has a custom prompt, so that the prompt
+// is kept.
+func (n Notary) keepPrompt(src string, dst Venv) Venv {
+	if m, ok := n.metadata[src]; ok && m.Options.Prompt != "" {
+		dst.Name = ""
 	}
-	venv, err = addVersion(venv)
+	return dst
+}
+
+// RelinkLocal re-keys the local environments registered for oldDir to newDir,
+// e.g. after the project directory has been moved.
+func (n *Notary) RelinkLocal(oldDir, newDir string) error {
+	oldName, err := createLocalName(oldDir, "")
 	if err != nil {
 		return err
 	}
-	_, ok := n.venvList[venv.Path]
-	if ok {
-		return n.delete(venv)
-	} else {
-		return fmt.Errorf("No environment with name '%s' is registered with this Python version.", name)
+	// every variant of the old directory is moved
+	venvs := []string{}
+	for _, v := range n.ListLocal() {
+		name, _ := ExtractVersion(filepath.Base(v))
+		if base, _ := SplitVariant(name); base == oldName {
+			venvs = append(venvs, v)
+		}
+	}
+	if len(venvs) == 0 {
+		return VenvNotRegisteredError{Message: fmt.Sprintf("No environment is registered for '%s'.", oldDir)}
+	}
+	slices.Sort(venvs)
+	moves := map[string]Venv{}
+	for _, v := range venvs {
+		if (Venv{Path: v}).IsActive() {
+			return errors.New("environment is active. Deactivate it before relinking it.")
+		}
+		name, version := ExtractVersion(filepath.Base(v))
+		_, variant := SplitVariant(name)
+		newVenv, err := n.GetLocalVenv(newDir, variant, "")
+		if err != nil {
+			return err
+		}
+		dst := n.keepPrompt(v, withVersion(newVenv, version))
+		if n.IsRegistered(dst) {
+			return fmt.Errorf("Environment '%s' is already registered for this directory with Python version %s.", newVenv.Name, version)
+		}
+		moves[v] = dst
+	}
+	// environments already moved are moved back if one fails, with their
+	// previous metadata, so that the project is not left split across two
+	// directories
+	moved := []Venv{}
+	undo := func() {
+		for _, back := range slices.Backward(moved) {
+			dst := moves[back.Path]
+			if m, ok := n.metadata[back.Path]; ok {
+				dst.WriteMetadata(m)
+			} else {
+				os.Remove(dst.MetadataPath())
+			}
+			dst.Move(back)
+		}
+	}
+	errs := []error{}
+	relinked := map[string]Metadata{}
+	for _, v := range venvs {
+		src := Venv{Path: v, Name: Venv{Path: v}.prompt()}
+		dst := moves[v]
+		err = src.Move(dst)
+		if err != nil {
+			undo()
+			return err
+		}
+		moved = append(moved, src)
+		metadata, ok := n.metadata[v]
+		if !ok {
+			metadata, err = newMetadata(Venv{Python: dst.baseExecutable()}, newDir)
+			if err != nil {
+				// the environment is moved anyway: it keeps its new project
+				// without interpreter details, and the others are moved too
+				errs = append(errs, fmt.Errorf("The interpreter of '%s' could not be probed: %w", dst.Path, err))
+				metadata = Metadata{}.stamp(newDir)
+			}
+		}
+		metadata.Project = newDir
+		err = dst.WriteMetadata(metadata)
+		if err != nil {
+			undo()
+			return err
+		}
+		relinked[v] = metadata
 	}
+	for v, metadata := range relinked {
+		delete(n.venvList, v)
+		delete(n.metadata, v)
+		n.venvList[moves[v].Path] = LocalLoc
+		n.metadata[moves[v].Path] = metadata
+	}
+	return errors.Join(errs...)
+}
+
+// RelinkCandidates returns the project directories of the orphaned local
+// environments with the same name as dir, i.e. the likely previous locations
+// of a project that has been moved to dir.
+func (n Notary) RelinkCandidates(dir string) []string {
+	name := NormalizeName(filepath.Base(dir))
+	candidates := []string{}
+	for _, v := range n.ListOrphaned() {
+		project := n.metadata[v].Project
+		if NormalizeName(filepath.Base(project)) != name || slices.Contains(candidates, project) {
+			continue
+		}
+		candidates = append(candidates, project)
+	}
+	slices.Sort(candidates)
+	return candidates
 }
 
 func (n Notary) ListGlobal() []string {
@@ -239,6 +549,28 @@ func (n Notary) ListLocal() []string {
 	return venvs
 }
 
+// ListOrphaned returns the local environments whose project directory no
+// longer exists, sorted by project. Environments without metadata are never
+// considered orphaned, since their project directory cannot be recovered from
+// the hashed name.
+func (n Notary) ListOrphaned() []string {
+	venvs := []string{}
+	for _, venv := range n.ListLocal() {
+		metadata, ok := n.metadata[venv]
+		if !ok || metadata.Project == "" {
+			continue
+		}
+		_, err := os.Stat(metadata.Project)
+		if errors.Is(err, fs.ErrNotExist) {
+			venvs = append(venvs, venv)
+		}
+	}
+	slices.SortFunc(venvs, func(a, b string) int {
+		return cmp.Or(strings.Compare(n.metadata[a].Project, n.metadata[b].Project), strings.Compare(a, b))
+	})
+	return venvs
+}
+
 func (n Notary) GetGlobalVenv(name, python string) (Venv, error) {
 	name = NormalizeName(name)
 	if name == "" {
@@ -249,12 +581,15 @@ func (n Notary) GetGlobalVenv(name, python string) (Venv, error) {
 	return venv, nil
 }
 
-func (n Notary) GetLocalVenv(currDir string, python string) (Venv, error) {
-	venvName, err := createLocalName(currDir)
+// GetLocalVenv returns the local environment of currDir, without Python
+// version. name selects one of several environments of the directory, and may
+// be empty.
+func (n Notary) GetLocalVenv(currDir, name, python string) (Venv, error) {
+	venvName, err := createLocalName(currDir, name)
 	if err != nil {
 		return Venv{}, err
 	}
-	venv := Venv{Path: filepath.Join(n.LocalDir(), venvName), Name: venvName, Python: python}
+	venv := Venv{Path: filepath.Join(n.LocalDir(), venvName), Name: RemoveHash(venvName), Python: python}
 
 	return venv, nil
 }
@@ -322,8 +657,6 @@ func (n Notary) GetHighestVersionRegistered(venv Venv, isLocal bool) Venv {
 		}
 		name, version := ExtractVersion(v)
 		if name == match {
-			// remove "py" in front of version
-			version = version[len(VersionPrefix):]
 			registeredVersions[version] = v
 			versionSlice = append(versionSlice, version)
 		}
@@ -331,127 +664,258 @@ func (n Notary) GetHighestVersionRegistered(venv Venv, isLocal bool) Venv {
 	if len(registeredVersions) == 0 {
 		return Venv{}
 	}
-	slices.SortFunc(versionSlice, SemanticVersioningSort)
-	highestVersion := versionSlice[len(versionSlice)-1]
-	venvPath := registeredVersions[highestVersion]
+	venvPath := registeredVersions[preferredVersionTag(versionSlice)]
 	return Venv{Path: venvPath}
 }
 
-func (n Notary) ActivateGlobal(name, python string) error {
-	venv, err := n.GetGlobalVenv(name, python)
+// GetMatchingVersionsOfVenv returns the registered Python versions of venv, an
+// environment without Python version, that match python, a version specifier
+// or an executable. Every version matches an empty python.
+func (n Notary) GetMatchingVersionsOfVenv(venv Venv, isLocal bool, python string) ([]string, error) {
+	matches, err := n.PythonMatcher(python)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if venvs := n.GetRegisteredVersionsOfVenv(venv, false); python == "" && len(venvs) >= 1 {
-		if len(venvs) == 1 {
-			registeredVenv := Venv{Path: venvs[0]}
-			return registeredVenv.Activate()
+	venvs := []string{}
+	for _, v := range n.GetRegisteredVersionsOfVenv(venv, isLocal) {
+		if matches(v) {
+			venvs = append(venvs, v)
 		}
-		return MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
 	}
-	venv, err = addVersion(venv)
+	return venvs, nil
+}
+
+// selectVersion returns the single registered Python version of venv matching
+// python. The boolean is false if no version matches.
+func (n Notary) selectVersion(venv Venv, isLocal bool, python string) (Venv, bool, error) {
+	venvs, err := n.GetMatchingVersionsOfVenv(venv, isLocal, python)
 	if err != nil {
-		return err
+		return Venv{}, false, err
+	}
+	switch {
+	case len(venvs) == 0:
+		return Venv{}, false, nil
+	case len(venvs) == 1:
+		return Venv{Path: venvs[0]}, true, nil
+	case python == "":
+		return Venv{}, true, MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
+	default:
+		return Venv{}, true, MultipleVersionsError{fmt.Sprintf("Multiple Python versions of this environment match '%s'. Select one Python version.", python)}
 	}
-	if !n.IsRegistered(venv) {
-		return VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered with this Python version.", name)}
-	}
-	err = venv.Activate()
-	return err
 }
 
-func (n Notary) ActivateLocal(python string) error {
-	currDir, err := os.Getwd()
+// FindGlobal returns the registered global environment with this name and
+// Python version. If no Python version is given and a single version of the
+// environment is registered, that version is returned.
+func (n Notary) FindGlobal(name, python string) (Venv, error) {
+	venv, err := n.GetGlobalVenv(name, python)
 	if err != nil {
-		return err
+		return Venv{}, err
+	}
+	venv, ok, err := n.selectVersion(venv, false, python)
+	if err != nil {
+		return Venv{}, err
 	}
-	// walk up the filesystem to find local envs in parent directories
+	if !ok {
+		return Venv{}, VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered with this Python version.", name)}
+	}
+	return venv, nil
+}
+
+// FindLocal returns the registered local environment of currDir, walking up
+// the filesystem to find local environments registered for parent directories.
+// Directories linked to a global environment resolve to that environment.
+func (n Notary) FindLocal(currDir, name, python string) (Venv, error) {
 	for currDir != filepath.Dir(currDir) {
-		venv, err := n.GetLocalVenv(currDir, python)
-		if err != nil {
-			return err
-		}
-		if venvs := n.GetRegisteredVersionsOfVenv(venv, true); python == "" && len(venvs) >= 1 {
-			if len(venvs) == 1 {
-				registeredVenv := Venv{Path: venvs[0]}
-				return registeredVenv.Activate()
+		// a link takes precedence over the local environment
+		if name == "" {
+			if linked, ok, err := n.findLink(currDir, python); ok {
+				return linked, err
 			}
-			return MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
 		}
-		venv, err = addVersion(venv)
+		venv, err := n.GetLocalVenv(currDir, name, python)
 		if err != nil {
-			return err
+			return Venv{}, err
+		}
+		venv, ok, err := n.selectVersion(venv, true, python)
+		if err != nil {
+			return Venv{}, err
 		}
-		if !n.IsRegistered(venv) {
+		if !ok {
 			currDir = filepath.Dir(currDir)
 			continue
 		}
+		return venv, nil
+	}
+	return Venv{}, VenvNotRegisteredError{Message: "No environment is registered for this directory with this Python version."}
+}
+
+// VersionsGlobal returns the registered Python versions of the global
+// environment name, sorted by version.
+func (n Notary) VersionsGlobal(name string) ([]Venv, error) {
+	venv, err := n.GetGlobalVenv(name, "")
+	if err != nil {
+		return nil, err
+	}
+	venvs := sortedVersions(n.GetRegisteredVersionsOfVenv(venv, false))
+	if len(venvs) == 0 {
+		return nil, VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered.", name)}
+	}
+	return venvs, nil
+}
 
-		err = venv.Activate()
+// VersionsLocal returns the registered Python versions of the local
+// environment of currDir, sorted by version. Like FindLocal, it walks up the
+// filesystem and follows links.
+func (n Notary) VersionsLocal(currDir, name string) ([]Venv, error) {
+	for currDir != filepath.Dir(currDir) {
+		if l, ok := n.links[currDir]; ok && name == "" {
+			return n.VersionsGlobal(l.Name)
+		}
+		venv, err := n.GetLocalVenv(currDir, name, "")
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return nil
+		if venvs := n.GetRegisteredVersionsOfVenv(venv, true); len(venvs) > 0 {
+			return sortedVersions(venvs), nil
+		}
+		currDir = filepath.Dir(currDir)
 	}
-	return VenvNotRegisteredError{Message: "No environment is registered for current directory with this Python version."}
+	return nil, VenvNotRegisteredError{Message: "No environment is registered for this directory."}
 }
 
-func (n Notary) RunGlobal(name, python, cmd string, args ...string) error {
-	venv, err := n.GetGlobalVenv(name, python)
+func sortedVersions(paths []string) []Venv {
+	slices.SortFunc(paths, func(a, b string) int {
+		_, versionA := ExtractVersion(a)
+		_, versionB := ExtractVersion(b)
+		return CompareVersionTags(versionA, versionB)
+	})
+	venvs := []Venv{}
+	for _, p := range paths {
+		venvs = append(venvs, Venv{Path: p})
+	}
+	return venvs
+}
+
+// ResolveLocal returns the registered local environment that local commands run
+// from dir use, honouring the Python version of the project file when python is
+// empty.
+func (n Notary) ResolveLocal(dir, name, python string) (Venv, error) {
+	currDir, err := filepath.Abs(dir)
+	if err != nil {
+		return Venv{}, err
+	}
+	python, err = projectPython(currDir, python)
+	if err != nil {
+		return Venv{}, err
+	}
+	return n.FindLocal(currDir, name, python)
+}
+
+func (n Notary) ActivateGlobal(name, python string) error {
+	venv, err := n.FindGlobal(name, python)
 	if err != nil {
 		return err
 	}
-	if venvs := n.GetRegisteredVersionsOfVenv(venv, false); python == "" && len(venvs) >= 1 {
-		if len(venvs) == 1 {
-			registeredVenv := Venv{Path: venvs[0]}
-			return registeredVenv.Run(cmd, args...)
-		}
-		return MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
+	return venv.Activate()
+}
+
+func (n Notary) ActivateLocal(dir, name, python string) error {
+	venv, err := n.ResolveLocal(dir, name, python)
+	if err != nil {
+		return err
+	}
+	return venv.Activate()
+}
+
+// ActivationCodeGlobal returns the code activating a global environment in the
+// current shell.
+func (n Notary) ActivationCodeGlobal(name, python string) (string, error) {
+	venv, err := n.FindGlobal(name, python)
+	if err != nil {
+		return "", err
 	}
-	venv, err = addVersion(venv)
+	return venv.ActivationCode()
+}
+
+// ActivationCodeLocal returns the code activating the local environment of dir
+// in the current shell.
+func (n Notary) ActivationCodeLocal(dir, name, python string) (string, error) {
+	venv, err := n.ResolveLocal(dir, name, python)
+	if err != nil {
+		return "", err
+	}
+	return venv.ActivationCode()
+}
+
+// SwitchGlobal replaces the shell started by vn activate with one in which a
+// global environment is active.
+func (n Notary) SwitchGlobal(name, python string) error {
+	venv, err := n.FindGlobal(name, python)
 	if err != nil {
 		return err
 	}
-	if !n.IsRegistered(venv) {
-		return VenvNotRegisteredError{Message: fmt.Sprintf("No environment with name '%s' is registered with this Python version.", name)}
+	return venv.Switch()
+}
+
+// SwitchLocal replaces the shell started by vn activate with one in which the
+// local environment of dir is active.
+func (n Notary) SwitchLocal(dir, name, python string) error {
+	venv, err := n.ResolveLocal(dir, name, python)
+	if err != nil {
+		return err
 	}
-	err = venv.Run(cmd, args...)
-	return err
+	return venv.Switch()
 }
-func (n Notary) RunLocal(python, cmd string, args ...string) error {
-	currDir, err := os.Getwd()
+
+func (n Notary) RunGlobal(name, python, cmd string, args ...string) error {
+	venv, err := n.FindGlobal(name, python)
 	if err != nil {
 		return err
 	}
-	// walk up the filesystem to find local envs in parent directories
-	for currDir != filepath.Dir(currDir) {
-		venv, err := n.GetLocalVenv(currDir, python)
-		if err != nil {
-			return err
-		}
-		if venvs := n.GetRegisteredVersionsOfVenv(venv, true); python == "" && len(venvs) >= 1 {
-			if len(venvs) == 1 {
-				registeredVenv := Venv{Path: venvs[0]}
-				return registeredVenv.Run(cmd, args...)
-			}
-			return MultipleVersionsError{"Multiple Python versions associated with this environment. Select one Python version."}
-		}
-		venv, err = addVersion(venv)
-		if err != nil {
-			return err
-		}
-		if !n.IsRegistered(venv) {
-			currDir = filepath.Dir(currDir)
-			continue
-		}
+	return venv.Run(cmd, args...)
+}
 
-		err = venv.Run(cmd, args...)
+func (n Notary) RunLocal(dir, name, python, cmd string, args ...string) error {
+	venv, err := n.ResolveLocal(dir, name, python)
+	if err != nil {
+		return err
+	}
+	return venv.Run(cmd, args...)
+}
+
+// Clone creates a new registered environment at dst with the same interpreter
+// and installed distributions as the registered environment src. dst is an
+// environment without Python version, as returned by GetGlobalVenv or
+// GetLocalVenv; it gets the same Python version as src. project is the
+// directory the clone belongs to, and must be empty for global environments.
+func (n *Notary) Clone(src, dst Venv, project string) error {
+	if !n.IsRegistered(src) {
+		return VenvNotRegisteredError{Message: "Source environment is not registered."}
+	}
+	_, version := ExtractVersion(src.Path)
+	dst = n.keepPrompt(src.Path, withVersion(dst, version))
+	if n.IsRegistered(dst) {
+		return errors.New("Destination environment already exists with this Python version.")
+	}
+	loc := GlobalLoc
+	if project != "" {
+		loc = LocalLoc
+	}
+	metadata, ok := n.metadata[src.Path]
+	if !ok {
+		var err error
+		metadata, err = newMetadata(Venv{Python: src.baseExecutable()}, project)
 		if err != nil {
 			return err
 		}
-		return nil
 	}
-	return VenvNotRegisteredError{Message: "No environment is registered for current directory with this Python version."}
+	metadata = metadata.stamp(project)
+	err := src.Clone(dst)
+	if err != nil {
+		return err
+	}
+	return n.register(dst, loc, metadata)
 }
 
 func (n Notary) GetActiveEnv() (Venv, error) {
@@ -463,44 +927,84 @@ func (n Notary) GetActiveEnv() (Venv, error) {
 	return Venv{}, errors.New("No active registered virtual environments.")
 }
 
+// VenvInfo describes a registered environment, as shown by vn info and vn list
+// --json. The fields from the metadata are empty for environments created by
+// older versions of vn.
+type VenvInfo struct {
+	Path           string         `json:"path"`
+	Name           string         `json:"name"`
+	Executable     string         `json:"version"`
+	Type           Location       `json:"type"`
+	Variant        string         `json:"variant,omitempty"`
+	Project        string         `json:"project,omitempty"`
+	Python         string         `json:"python,omitempty"`
+	PythonVersion  string         `json:"python_version,omitempty"`
+	Implementation string         `json:"implementation,omitempty"`
+	ABIFlags       string         `json:"abi_flags,omitempty"`
+	Creator        string         `json:"creator,omitempty"`
+	Options        *CreateOptions `json:"options,omitempty"`
+	CreatedAt      *time.Time     `json:"created_at,omitempty"`
+	CreatedBy      string         `json:"created_by,omitempty"`
+	VnVersion      string         `json:"vn_version,omitempty"`
+	LinkedDirs     []string       `json:"linked_dirs,omitempty"`
+}
+
+// Info returns the description of the registered environment at path.
+func (n Notary) Info(path string) VenvInfo {
+	t := n.venvList[path]
+	name, version := ExtractVersion(filepath.Base(path))
+	var variant string
+	if t == LocalLoc {
+		name, variant = SplitVariant(RemoveHash(name))
+	}
+	build := n.Build(path)
+	info := VenvInfo{
+		Path:           path,
+		Type:           t,
+		Name:           name,
+		Variant:        variant,
+		Executable:     version,
+		Implementation: build.Implementation,
+		ABIFlags:       build.ABIFlags,
+	}
+	if m, ok := n.metadata[path]; ok {
+		info.Project = m.Project
+		info.Python = m.Python
+		info.PythonVersion = m.Version
+		info.Creator = m.Creator
+		info.Options = &m.Options
+		info.CreatedAt = &m.CreatedAt
+		info.CreatedBy = m.CreatedBy
+		info.VnVersion = m.VnVersion
+	}
+	if t == GlobalLoc {
+		for _, l := range n.ListLinks() {
+			if l.Name == name {
+				info.LinkedDirs = append(info.LinkedDirs, l.Dir)
+			}
+		}
+	}
+	return info
+}
+
 func (n Notary) ToJson(global, local bool, pythonExec string) (string, error) {
 	n.GetVenvs()
-	type qualifiedVenv struct {
-		Path       string   `json:"path"`
-		Name       string   `json:"name"`
-		Executable string   `json:"version"`
-		Type       Location `json:"type"`
-	}
-	jsonList := []qualifiedVenv{}
-	pythonVersion := ""
-	if pythonExec != "" {
-		var err error
-		pythonVersion, err = PythonVersion(pythonExec)
-		if err != nil {
-			return "", err
-		}
+	jsonList := []VenvInfo{}
+	matches, err := n.PythonMatcher(pythonExec)
+	if err != nil {
+		return "", err
 	}
 	for p, t := range n.venvList {
-		name, version := ExtractVersion(filepath.Base(p))
 		if t == GlobalLoc && local {
 			continue
 		}
 		if t == LocalLoc && global {
 			continue
 		}
-		if pythonVersion != "" && pythonVersion != version {
+		if !matches(p) {
 			continue
 		}
-		if t == LocalLoc {
-			name = RemoveHash(name)
-		}
-		v := qualifiedVenv{
-			Path:       p,
-			Type:       t,
-			Name:       name,
-			Executable: version,
-		}
-		jsonList = append(jsonList, v)
+		jsonList = append(jsonList, n.Info(p))
 	}
 	jsonOutput, err := json.MarshalIndent(jsonList, "", "  ")
 	return string(jsonOutput), err
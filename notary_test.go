@@ -1,9 +1,17 @@
 package venv
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/azr4e1/venv-notary/shell"
 )
 
 func TestCreatesAVirtualEnv(t *testing.T) {
@@ -299,3 +307,987 @@ func TestExtractVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestLocalNames_KeepTheVariant(t *testing.T) {
+	t.Parallel()
+	name, err := createLocalName("/home/user/my project", "Docs Build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, variant := SplitVariant(name)
+	if variant != "docs_build" {
+		t.Errorf("variant: want docs_build, got %s", variant)
+	}
+	defaultName, err := createLocalName("/home/user/my project", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != defaultName {
+		t.Errorf("base: want %s, got %s", defaultName, base)
+	}
+	if got := RemoveHash(name); got != "my_project+docs_build" {
+		t.Errorf("name without hash: want my_project+docs_build, got %s", got)
+	}
+	noVersion, version := ExtractVersion(name + "-py3.12")
+	if noVersion != name || version != "py3.12" {
+		t.Errorf("name: want %s, got %s; version: want py3.12, got %s", name, noVersion, version)
+	}
+}
+
+func TestActivationScript_IsGeneratedWhenMissing(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := Venv{Path: path.Join(dir, "env")}
+	err = v.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"xonsh", "elvish", "nu"} {
+		sh, err := shell.Named(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		script, err := v.activationScript(sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(script)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(string(content), v.Path) {
+			t.Errorf("%s: activation script doesn't point to the environment", name)
+		}
+	}
+}
+
+func TestCreateGlobal_WritesMetadata(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobal("meta", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	venvs := notary.ListGlobal()
+	if len(venvs) != 1 {
+		t.Fatalf("want 1 global venv, got %d", len(venvs))
+	}
+	// reload from disk
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, ok := notary.Metadata(venvs[0])
+	if !ok {
+		t.Fatal("metadata has not been loaded")
+	}
+	if metadata.Version == "" || metadata.Python == "" {
+		t.Errorf("incomplete metadata: %+v", metadata)
+	}
+	if metadata.VnVersion != Version {
+		t.Errorf("want vn version '%s', got '%s'", Version, metadata.VnVersion)
+	}
+	if metadata.CreatedAt.IsZero() {
+		t.Error("creation time has not been recorded")
+	}
+}
+
+func TestNewMetadata_RecordsTheBaseInterpreter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake shim is a shell script")
+	}
+	dir := t.TempDir()
+	base := filepath.Join(dir, "python 3.99", "bin", "python3.99")
+	shim := filepath.Join(dir, "python")
+	script := "#!/bin/sh\nprintf 'cpython\\n3.99.1\\n-\\n" + base + "\\n'\n"
+	err := os.WriteFile(shim, []byte(script), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := newMetadata(Venv{Python: shim}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Python != base || metadata.Version != "3.99.1" {
+		t.Errorf("want the base interpreter %s at 3.99.1, got %+v", base, metadata)
+	}
+}
+
+func TestCreateGlobalWith_RecordsTheCreatorUsed(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := CreateOptions{Creator: CreatorVirtualenv}
+	err = notary.CreateGlobalWith("backend", "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	venvs := notary.ListGlobal()
+	if len(venvs) != 1 {
+		t.Fatalf("want 1 global venv, got %d", len(venvs))
+	}
+	metadata, _ := notary.Metadata(venvs[0])
+	// virtualenv is replaced by another backend if it is not installed
+	want, err := SelectCreator(metadata.Python, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Creator != want.Name() {
+		t.Errorf("want creator %s recorded, got '%s'", want.Name(), metadata.Creator)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := notary.Metadata(venvs[0]); reloaded.Creator != metadata.Creator {
+		t.Errorf("want creator %s after reloading, got '%s'", metadata.Creator, reloaded.Creator)
+	}
+}
+
+func TestCloneVenv_RelocatesTheCopy(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := Venv{Path: path.Join(dir, "src"), Name: "src"}
+	err = src.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := Venv{Path: path.Join(dir, "dst"), Name: "dst"}
+	err = src.Clone(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dst.IsVenv() {
+		t.Fatal("clone is not an environment")
+	}
+	for _, f := range []string{"pyvenv.cfg", "bin/activate", "bin/pip"} {
+		content, err := os.ReadFile(path.Join(dst.Path, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(content), src.Path) {
+			t.Errorf("%s still refers to the source environment", f)
+		}
+	}
+	if got := dst.prompt(); got != "dst" {
+		t.Errorf("want prompt 'dst', got '%s'", got)
+	}
+}
+
+func TestCreateWith_KeepsTheOptions(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: path.Join(dir, "notary")}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := CreateOptions{SystemSitePackages: true, Prompt: "my tool"}
+	err = notary.CreateGlobalWith("tool", "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldProject, newProject := path.Join(dir, "old"), path.Join(dir, "new")
+	for _, p := range []string{oldProject, newProject} {
+		err = os.Mkdir(p, os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = notary.CreateLocalWith(oldProject, "", "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reload from disk
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool, err := notary.FindGlobal("tool", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata, _ := notary.Metadata(tool.Path); metadata.Options != opts {
+		t.Errorf("want options %+v recorded, got %+v", opts, metadata.Options)
+	}
+	config, err := tool.config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config["include-system-site-packages"] != "true" {
+		t.Errorf("want the system site-packages included, got pyvenv.cfg %v", config)
+	}
+
+	err = notary.RenameGlobal("tool", "renamed", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := notary.FindGlobal("renamed", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy, err := notary.GetGlobalVenv("copy", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Clone(renamed, copy, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy, err = notary.FindGlobal("copy", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.RelinkLocal(oldProject, newProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relinked, err := notary.FindLocal(newProject, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []Venv{renamed, copy, relinked} {
+		if prompt := v.prompt(); prompt != opts.Prompt {
+			t.Errorf("%s: want prompt '%s', got '%s'", v.Path, opts.Prompt, prompt)
+		}
+		if metadata, _ := notary.Metadata(v.Path); metadata.Options != opts {
+			t.Errorf("%s: want options %+v kept, got %+v", v.Path, opts, metadata.Options)
+		}
+	}
+}
+
+func TestNotaryClone_RelocatesEditableInstalls(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobal("src", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := notary.FindGlobal("src", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an editable install checked out in the src directory of the
+	// environment, as pip does for VCS requirements
+	pkgDir := path.Join(src.Path, "src", "mypkg")
+	err = os.MkdirAll(path.Join(pkgDir, "mypkg"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(pkgDir, "mypkg", "__init__.py"), nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sitePackages, err := filepath.Glob(path.Join(src.Path, "lib", "*", "site-packages"))
+	if err != nil || len(sitePackages) != 1 {
+		t.Fatalf("want one site-packages, got %v (%v)", sitePackages, err)
+	}
+	err = os.WriteFile(path.Join(sitePackages[0], "__editable__.mypkg-0.1.pth"), []byte(pkgDir+"\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	distInfo := path.Join(sitePackages[0], "mypkg-0.1.dist-info")
+	err = os.Mkdir(distInfo, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directURL := fmt.Sprintf(`{"url": "file://%s", "dir_info": {"editable": true}}`, pkgDir)
+	err = os.WriteFile(path.Join(distInfo, "direct_url.json"), []byte(directURL), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := notary.GetGlobalVenv("dst", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.Clone(src, dst, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err = notary.FindGlobal("dst", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(path.Join(dst.Path, "bin", "python"), "-c", "import mypkg; print(mypkg.__file__)").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(output)); !strings.HasPrefix(got, dst.Path+"/") {
+		t.Errorf("want mypkg imported from the clone, got %s", got)
+	}
+	content, err := os.ReadFile(strings.Replace(path.Join(distInfo, "direct_url.json"), src.Path, dst.Path, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), src.Path) {
+		t.Errorf("direct_url.json still refers to the source environment: %s", content)
+	}
+}
+
+func TestRenameGlobal_MovesTheVenv(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobal("old", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateGlobal("taken", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.RenameGlobal("old", "taken", "")
+	if err == nil {
+		t.Error("should not rename onto an existing environment")
+	}
+	err = notary.RenameGlobal("old", "new", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	venv, err := notary.FindGlobal("new", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := venv.prompt(); !strings.HasPrefix(got, "new-") {
+		t.Errorf("prompt has not been renamed: '%s'", got)
+	}
+	_, err = notary.FindGlobal("old", "")
+	if err == nil {
+		t.Error("old environment is still registered")
+	}
+}
+
+func TestRenameGlobal_MovesBackTheVersionsOnFailure(t *testing.T) {
+	t.Parallel()
+	notary := Notary{venvDir: t.TempDir()}
+	err := notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary.venvList = map[string]Location{}
+	notary.metadata = map[string]Metadata{}
+	// fake environments: py3.12 is moved first, and py3.9 cannot be
+	// relocated since its pyvenv.cfg is a directory
+	for _, name := range []string{"old-py3.12", "old-py3.9"} {
+		p := path.Join(notary.GlobalDir(), name)
+		err = os.MkdirAll(path.Join(p, "bin"), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []string{"bin/activate", "bin/python", "pyvenv.cfg"} {
+			err = os.WriteFile(path.Join(p, f), []byte("home = "+p+"\n"), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		notary.venvList[p] = GlobalLoc
+	}
+	broken := path.Join(notary.GlobalDir(), "old-py3.9", "pyvenv.cfg")
+	os.Remove(broken)
+	err = os.Mkdir(broken, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notary.RenameGlobal("old", "new", "")
+	if err == nil {
+		t.Fatal("want an error, got nil")
+	}
+	for _, name := range []string{"old-py3.12", "old-py3.9"} {
+		p := path.Join(notary.GlobalDir(), name)
+		if !(Venv{Path: p}).IsVenv() {
+			t.Errorf("%s has not been moved back", name)
+		}
+		if _, ok := notary.venvList[p]; !ok {
+			t.Errorf("%s is no longer registered", name)
+		}
+	}
+	content, err := os.ReadFile(path.Join(notary.GlobalDir(), "old-py3.12", "pyvenv.cfg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "new-py3.12") {
+		t.Errorf("pyvenv.cfg still refers to the new name: %s", content)
+	}
+	entries, err := os.ReadDir(notary.GlobalDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("want the 2 old environments, got %v", entries)
+	}
+}
+
+func TestRelinkLocal_MovesTheVenvToTheNewProject(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: path.Join(dir, "notary")}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDir := path.Join(dir, "old", "project")
+	newDir := path.Join(dir, "new", "project")
+	err = os.MkdirAll(newDir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := notary.GetLocalVenv(oldDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = addVersion(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.WriteMetadata(Metadata{Project: oldDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidates := notary.RelinkCandidates(newDir)
+	if len(candidates) != 1 || candidates[0] != oldDir {
+		t.Fatalf("want candidates [%s], got %v", oldDir, candidates)
+	}
+	err = notary.RelinkLocal(oldDir, newDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relinked, err := notary.FindLocal(newDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := relinked.ReadMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Project != newDir {
+		t.Errorf("want project '%s', got '%s'", newDir, metadata.Project)
+	}
+	if len(notary.RelinkCandidates(newDir)) != 0 {
+		t.Error("relinked environment is still orphaned")
+	}
+}
+
+func TestRelinkLocal_ReportsVenvsWhoseInterpreterIsGone(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	notary := Notary{venvDir: path.Join(dir, "notary")}
+	err := notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDir := path.Join(dir, "old", "project")
+	newDir := path.Join(dir, "new", "project")
+	v, err := notary.GetLocalVenv(oldDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a fake environment without metadata, created by an interpreter that
+	// has been uninstalled since
+	v = withVersion(v, "py3.12")
+	err = os.MkdirAll(path.Join(v.Path, "bin"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"bin/activate", "bin/python"} {
+		err = os.WriteFile(path.Join(v.Path, f), nil, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.WriteFile(path.Join(v.Path, "pyvenv.cfg"), []byte("home = /gone/bin\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notary.RelinkLocal(oldDir, newDir)
+	if err == nil || !strings.Contains(err.Error(), "could not be probed") {
+		t.Errorf("want an error about the interpreter, got %v", err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relinked, err := notary.FindLocal(newDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, ok := notary.Metadata(relinked.Path)
+	if !ok || metadata.Project != newDir {
+		t.Errorf("want project '%s', got %+v", newDir, metadata)
+	}
+}
+
+func TestRelinkLocal_MovesBackTheVenvsOnFailure(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	notary := Notary{venvDir: path.Join(dir, "notary")}
+	err := notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary.venvList = map[string]Location{}
+	notary.metadata = map[string]Metadata{}
+	oldDir := path.Join(dir, "old", "project")
+	newDir := path.Join(dir, "new", "project")
+	v, err := notary.GetLocalVenv(oldDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fake environments: py3.12 is moved first, and py3.9 cannot be
+	// relocated since its pyvenv.cfg is a directory
+	olds := []Venv{withVersion(v, "py3.12"), withVersion(v, "py3.9")}
+	for _, old := range olds {
+		err = os.MkdirAll(path.Join(old.Path, "bin"), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []string{"bin/activate", "bin/python", "pyvenv.cfg"} {
+			err = os.WriteFile(path.Join(old.Path, f), []byte("home = "+old.Path+"\n"), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = notary.register(old, LocalLoc, Metadata{Project: oldDir, Python: "/usr/bin/python3"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	broken := path.Join(olds[1].Path, "pyvenv.cfg")
+	os.Remove(broken)
+	err = os.Mkdir(broken, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = notary.RelinkLocal(oldDir, newDir)
+	if err == nil {
+		t.Fatal("want an error, got nil")
+	}
+	for _, old := range olds {
+		if !old.IsVenv() {
+			t.Errorf("%s has not been moved back", old.Path)
+		}
+		if _, ok := notary.venvList[old.Path]; !ok {
+			t.Errorf("%s is no longer registered", old.Path)
+		}
+		metadata, err := old.ReadMetadata()
+		if err != nil || metadata.Project != oldDir {
+			t.Errorf("%s: want the project '%s' kept, got %+v (%v)", old.Path, oldDir, metadata, err)
+		}
+	}
+	if len(notary.venvList) != 2 {
+		t.Errorf("want the 2 old environments registered, got %v", notary.venvList)
+	}
+	entries, err := os.ReadDir(notary.LocalDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("want the 2 old environments, got %v", entries)
+	}
+}
+
+func TestNewNotary_HonoursHomeOverride(t *testing.T) {
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(HomeEnv, dir)
+	notary, err := NewNotary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notary.Dir() != dir {
+		t.Errorf("want notary dir '%s', got '%s'", dir, notary.Dir())
+	}
+	wantGlobalDir := path.Join(dir, "global")
+	if notary.GlobalDir() != wantGlobalDir {
+		t.Errorf("want global dir path '%s', got '%s'", wantGlobalDir, notary.GlobalDir())
+	}
+	if _, err := os.Stat(wantGlobalDir); err != nil {
+		t.Error("global env dir has not been created")
+	}
+}
+
+func TestConfigDirs_HonourXDG(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are only used on linux")
+	}
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", path.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", path.Join(dir, "cache"))
+	for name, f := range map[string]func() (string, error){"config": ConfigDir, "cache": CacheDir} {
+		got, err := f()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := path.Join(dir, name, "venv-notary")
+		if got != want {
+			t.Errorf("want %s dir '%s', got '%s'", name, want, got)
+		}
+	}
+}
+
+func TestActivate_RefusesNestedShells(t *testing.T) {
+	t.Setenv(shell.ActiveEnv, "/venvs/other-py3.12")
+	err := Venv{Path: t.TempDir()}.Activate()
+	if err == nil || !strings.Contains(err.Error(), "/venvs/other-py3.12") {
+		t.Errorf("want an error naming the active environment, got %v", err)
+	}
+}
+
+func TestDeactivatedEnviron_RemovesTheActivation(t *testing.T) {
+	bin := path.Join("/venvs", "lib-py3.12", getVenvExecDir())
+	t.Setenv("VIRTUAL_ENV", "/venvs/lib-py3.12")
+	t.Setenv("VIRTUAL_ENV_PROMPT", "lib")
+	t.Setenv(shell.ActiveEnv, "/venvs/lib-py3.12")
+	t.Setenv("PATH", strings.Join([]string{bin, "/usr/bin", bin + "-not", "/bin"}, string(os.PathListSeparator)))
+	// a variable of the environment replacing the user's value
+	t.Setenv("DATABASE_URL", "postgres://venv")
+	t.Setenv(shell.RestoreEnv, `{"DATABASE_URL":"postgres://user"}`)
+
+	environ := map[string]string{}
+	for _, e := range deactivatedEnviron() {
+		key, value, _ := strings.Cut(e, "=")
+		if _, ok := environ[key]; ok {
+			t.Errorf("%s is set twice", key)
+		}
+		environ[key] = value
+	}
+	for _, key := range []string{"VIRTUAL_ENV", "VIRTUAL_ENV_PROMPT", shell.ActiveEnv, shell.RestoreEnv} {
+		if value, ok := environ[key]; ok {
+			t.Errorf("%s is still set to '%s'", key, value)
+		}
+	}
+	wantPath := strings.Join([]string{"/usr/bin", bin + "-not", "/bin"}, string(os.PathListSeparator))
+	if environ["PATH"] != wantPath {
+		t.Errorf("want PATH '%s', got '%s'", wantPath, environ["PATH"])
+	}
+	if environ["DATABASE_URL"] != "postgres://user" {
+		t.Errorf("want the user's DATABASE_URL restored, got '%s'", environ["DATABASE_URL"])
+	}
+}
+
+func TestSwitchEnviron_OverridesTheUserValues(t *testing.T) {
+	t.Setenv("VIRTUAL_ENV", "/venvs/lib-py3.12")
+	t.Setenv(shell.ActiveEnv, "/venvs/lib-py3.12")
+	// set by the user, and by the previous environment
+	t.Setenv("DEBUG", "0")
+	t.Setenv("DATABASE_URL", "postgres://lib")
+	t.Setenv(shell.RestoreEnv, `{"DATABASE_URL":"postgres://user"}`)
+
+	v := Venv{Path: "/venvs/api-py3.12"}
+	environ := map[string]string{}
+	for _, e := range v.switchEnviron(map[string]string{"DEBUG": "1", "DATABASE_URL": "postgres://api"}) {
+		key, value, _ := strings.Cut(e, "=")
+		if _, ok := environ[key]; ok {
+			t.Errorf("%s is set twice", key)
+		}
+		environ[key] = value
+	}
+	want := map[string]string{
+		"DEBUG":          "1",
+		"DATABASE_URL":   "postgres://api",
+		shell.ActiveEnv:  v.Path,
+		shell.RestoreEnv: `{"DATABASE_URL":"postgres://user","DEBUG":"0"}`,
+	}
+	for key, value := range want {
+		if environ[key] != value {
+			t.Errorf("want %s '%s', got '%s'", key, value, environ[key])
+		}
+	}
+}
+
+func TestSwitch_IsOnlyAllowedInPlaceOfTheActivatedShell(t *testing.T) {
+	if _, err := shell.NewShell(); err != nil {
+		t.Skip("no shell available")
+	}
+	dir := t.TempDir()
+	v := Venv{Path: path.Join(dir, "lib-py3.12")}
+	// a fake environment, with the activation scripts of every shell
+	err := os.MkdirAll(path.Join(v.Path, getVenvExecDir()), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"activate", "activate.fish", "Activate.ps1", "activate.nu", "activate.csh", "activate.xsh", "activate.elv", getVenvPythonExec()} {
+		err = os.WriteFile(path.Join(v.Path, getVenvExecDir(), f), nil, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("VIRTUAL_ENV", "")
+
+	t.Setenv(shell.ActiveEnv, "")
+	err = v.CheckSwitch()
+	if err == nil || !strings.Contains(err.Error(), "Not in a shell started by vn activate") {
+		t.Errorf("want an error outside of a shell started by vn, got %v", err)
+	}
+	err = v.Switch()
+	if err == nil || !strings.Contains(err.Error(), "Not in a shell started by vn activate") {
+		t.Errorf("want Switch to check the shell, got %v", err)
+	}
+
+	t.Setenv(shell.ActiveEnv, "/venvs/other-py3.12")
+	err = v.CheckSwitch()
+	if err != nil {
+		t.Errorf("want the switch allowed from a shell started by vn, got %v", err)
+	}
+	// vn has not replaced the shell: its parent is not the vn that started it
+	t.Setenv(shell.ActivePidEnv, "0")
+	err = v.Switch()
+	if err == nil || !strings.Contains(err.Error(), "exec vn switch") {
+		t.Errorf("want an error when the shell is not replaced, got %v", err)
+	}
+	err = Venv{Path: path.Join(dir, "missing")}.CheckSwitch()
+	if err == nil {
+		t.Error("want an error for a missing environment, got nil")
+	}
+}
+
+func TestRun_ReturnsTheExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command uses sh")
+	}
+	venv := Venv{Path: t.TempDir()}
+	err := venv.Run("sh", "-c", "exit 3")
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("want exit status 3, got %v", err)
+	}
+	err = venv.Run("sh", "-c", "kill -TERM $$")
+	if !errors.As(err, &exitErr) || exitErr.Code != 128+15 {
+		t.Fatalf("want exit status 143, got %v", err)
+	}
+	err = venv.Run("sh", "-c", "exit 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVersionsGlobal_AreSortedByVersion(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// registered by hand, since only the names matter
+	notary.venvList = map[string]Location{}
+	for _, name := range []string{"lib-py3.9", "lib-py3.12", "lib-py3.10", "other-py3.11"} {
+		notary.venvList[path.Join(notary.GlobalDir(), name)] = GlobalLoc
+	}
+	venvs, err := notary.VersionsGlobal("lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, v := range venvs {
+		got = append(got, path.Base(v.Path))
+	}
+	want := []string{"lib-py3.9", "lib-py3.10", "lib-py3.12"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	_, err = notary.VersionsGlobal("missing")
+	if !errors.As(err, &VenvNotRegisteredError{}) {
+		t.Errorf("want VenvNotRegisteredError, got %v", err)
+	}
+}
+
+func TestLocalMethods_UseTheGivenDirectory(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectDir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: dir}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.CreateLocal(projectDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	venv, err := notary.ResolveLocal(path.Join(projectDir, "."), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := venv.CombinedOutput("python", "-c", "import os; print(os.getcwd())")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cwd, _ := os.Getwd(); strings.TrimSpace(string(output)) != cwd {
+		t.Errorf("want the command to run in %s, got %s", cwd, output)
+	}
+	err = venv.RunWith(RunOptions{Dir: projectDir}, "python", "-c", "import os, sys; sys.exit(os.getcwd() != sys.argv[1])", projectDir)
+	if err != nil {
+		t.Errorf("want the command to run in %s: %v", projectDir, err)
+	}
+	err = notary.DeleteLocal(projectDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notary.ListLocal()) != 0 {
+		t.Error("local environment should be deleted")
+	}
+}
+
+func TestListOrphaned_ReportsTheVenvsOfRemovedProjects(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notary := Notary{venvDir: path.Join(dir, "notary")}
+	err = notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := []string{path.Join(dir, "b"), path.Join(dir, "a"), path.Join(dir, "kept")}
+	for _, p := range projects {
+		err = os.Mkdir(p, os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = notary.CreateLocal(p, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if orphans := notary.ListOrphaned(); len(orphans) != 0 {
+		t.Fatalf("want no orphans, got %v", orphans)
+	}
+	for _, p := range projects[:2] {
+		err = os.RemoveAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orphans := notary.ListOrphaned()
+	if len(orphans) != 2 {
+		t.Fatalf("want 2 orphans, got %v", orphans)
+	}
+	for i, want := range []string{path.Join(dir, "a"), path.Join(dir, "b")} {
+		metadata, _ := notary.Metadata(orphans[i])
+		if metadata.Project != want {
+			t.Errorf("orphan %d: want project %s, got %s", i, want, metadata.Project)
+		}
+	}
+	for _, o := range orphans {
+		err = Venv{Path: o}.Delete()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = notary.GetVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orphans := notary.ListOrphaned(); len(orphans) != 0 {
+		t.Errorf("want no orphans after deleting them, got %v", orphans)
+	}
+	if _, err := notary.FindLocal(projects[2], "", ""); err != nil {
+		t.Errorf("the venv of the remaining project has been deleted: %v", err)
+	}
+}
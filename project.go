@@ -0,0 +1,105 @@
+package venv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+)
+
+const ProjectFile = ".vn.toml"
+
+var versionNumber = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// Project is a project file, pinning the settings of the local environment of
+// the directory tree it lives in.
+type Project struct {
+	// Dir is the directory containing the project file.
+	Dir string `toml:"-"`
+	// Python is a python executable, or a version such as "3.12".
+	Python string `toml:"python"`
+	// Name is the prompt of the environment.
+	Name string `toml:"name"`
+	// Requirements are requirement files to install in the environment,
+	// relative to Dir.
+	Requirements []string `toml:"requirements"`
+	// Creator is the preferred creator backend: venv, uv or virtualenv.
+	Creator string `toml:"creator"`
+}
+
+// FindProject walks up the filesystem from dir looking for a project file.
+// The boolean is false if there is none.
+func FindProject(dir string) (Project, bool, error) {
+	for {
+		project, err := ReadProject(filepath.Join(dir, ProjectFile))
+		if err == nil {
+			return project, true, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return Project{}, false, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Project{}, false, nil
+		}
+		dir = parent
+	}
+}
+
+// ReadProject parses the project file at path.
+func ReadProject(path string) (Project, error) {
+	var project Project
+	meta, err := toml.DecodeFile(path, &project)
+	if err != nil {
+		return Project{}, err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return Project{}, fmt.Errorf("unknown key '%s' in '%s'", undecoded[0], path)
+	}
+	project.Dir = filepath.Dir(path)
+	return project, nil
+}
+
+// Executable returns the python executable pinned by the project: versions
+// are mapped to the matching pythonX.Y executable, and relative paths are
+// resolved against the project directory.
+func (p Project) Executable() string {
+	switch {
+	case p.Python == "":
+		return ""
+	case versionNumber.MatchString(p.Python):
+		return "python" + p.Python
+	case filepath.Base(p.Python) != p.Python && !filepath.IsAbs(p.Python):
+		return filepath.Join(p.Dir, p.Python)
+	default:
+		return p.Python
+	}
+}
+
+// RequirementFiles returns the absolute paths of the requirement files.
+func (p Project) RequirementFiles() []string {
+	files := []string{}
+	for _, r := range p.Requirements {
+		if !filepath.IsAbs(r) {
+			r = filepath.Join(p.Dir, r)
+		}
+		files = append(files, r)
+	}
+	return files
+}
+
+// projectPython returns python, or the python executable pinned by the project
+// file of dir if python is empty.
+func projectPython(dir, python string) (string, error) {
+	if python != "" {
+		return python, nil
+	}
+	project, ok, err := FindProject(dir)
+	if err != nil || !ok {
+		return python, err
+	}
+	return project.Executable(), nil
+}
@@ -0,0 +1,80 @@
+package venv
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestFindProject_WalksUpTheFilesystem(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subDir := path.Join(dir, "a", "b")
+	err = os.MkdirAll(subDir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "python = \"3.12\"\nname = \"proj\"\nrequirements = [\"requirements.txt\"]\n"
+	err = os.WriteFile(path.Join(dir, ProjectFile), []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, ok, err := FindProject(subDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("project file not found")
+	}
+	if project.Dir != dir {
+		t.Errorf("want project dir '%s', got '%s'", dir, project.Dir)
+	}
+	if project.Executable() != "python3.12" {
+		t.Errorf("want executable 'python3.12', got '%s'", project.Executable())
+	}
+	files := project.RequirementFiles()
+	if len(files) != 1 || files[0] != path.Join(dir, "requirements.txt") {
+		t.Errorf("unexpected requirement files %v", files)
+	}
+}
+
+func TestReadProject_RejectsUnknownKeys(t *testing.T) {
+	t.Parallel()
+	dir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectPath := path.Join(dir, ProjectFile)
+	err = os.WriteFile(projectPath, []byte("pyhton = \"3.12\"\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadProject(projectPath)
+	if err == nil {
+		t.Error("should return error on unknown keys")
+	}
+}
+
+func TestProjectExecutable(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		Python string
+		Want   string
+	}{
+		{"", ""},
+		{"3", "python3"},
+		{"3.11", "python3.11"},
+		{"python3.9", "python3.9"},
+		{"/usr/bin/python3", "/usr/bin/python3"},
+		{".venv/bin/python", "/project/.venv/bin/python"},
+	}
+	for _, tc := range testCases {
+		got := Project{Dir: "/project", Python: tc.Python}.Executable()
+		if got != tc.Want {
+			t.Errorf("for '%s': want '%s', got '%s'", tc.Python, tc.Want, got)
+		}
+	}
+}
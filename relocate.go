@@ -0,0 +1,303 @@
+package venv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const PyvenvConfig = "pyvenv.cfg"
+
+// config returns the key-value pairs of the pyvenv.cfg file of the environment.
+func (v Venv) config() (map[string]string, error) {
+	file, err := os.Open(filepath.Join(v.Path, PyvenvConfig))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	config := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		config[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return config, scanner.Err()
+}
+
+// prompt returns the prompt the environment has been created with. venv
+// defaults to the name of the directory when no prompt is given.
+func (v Venv) prompt() string {
+	config, err := v.config()
+	if err != nil {
+		return filepath.Base(v.Path)
+	}
+	prompt, ok := config["prompt"]
+	if !ok {
+		return filepath.Base(v.Path)
+	}
+	return strings.Trim(prompt, `'"`)
+}
+
+// baseExecutable returns the interpreter the environment has been created from.
+func (v Venv) baseExecutable() string {
+	config, err := v.config()
+	if err != nil {
+		return ""
+	}
+	if executable, ok := config["executable"]; ok {
+		return executable
+	}
+	if home, ok := config["home"]; ok {
+		return filepath.Join(home, getVenvPythonExec())
+	}
+	return ""
+}
+
+// Clone copies the environment to dst and relocates the copy, so that the new
+// environment doesn't depend on the original one.
+func (v Venv) Clone(dst Venv) error {
+	if !v.IsVenv() {
+		return fmt.Errorf("'%s' is not a python environment!", v.Path)
+	}
+	_, err := os.Stat(dst.Path)
+	if err == nil {
+		return errors.New("Directory or file already exists with this name.")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	oldPrompt := v.prompt()
+	err = copyDir(v.Path, dst.Path)
+	if err != nil {
+		os.RemoveAll(dst.Path)
+		return err
+	}
+	err = dst.relocate(v.Path, oldPrompt)
+	if err != nil {
+		os.RemoveAll(dst.Path)
+		return err
+	}
+	return nil
+}
+
+// Move moves the environment to dst and relocates it.
+func (v Venv) Move(dst Venv) error {
+	if !v.IsVenv() {
+		return fmt.Errorf("'%s' is not a python environment!", v.Path)
+	}
+	if v.IsActive() {
+		return errors.New("environment is active. Deactivate it before moving it.")
+	}
+	_, err := os.Stat(dst.Path)
+	if err == nil {
+		return errors.New("Directory or file already exists with this name.")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	oldPrompt := v.prompt()
+	err = os.Rename(v.Path, dst.Path)
+	if err != nil {
+		return err
+	}
+	err = dst.relocate(v.Path, oldPrompt)
+	if err != nil {
+		// best effort to leave the environment as it was
+		if rerr := os.Rename(dst.Path, v.Path); rerr == nil {
+			v.relocate(dst.Path, dst.prompt())
+		}
+		return err
+	}
+	return nil
+}
+
+// relocate rewrites the absolute paths and the prompt embedded in an
+// environment that has been copied or moved from oldPath to its current path:
+// pyvenv.cfg, the activation scripts, the shebangs of the console scripts and
+// the files of site-packages that point to the environment, such as the .pth
+// files of editable installs checked out in its src directory. The prompt is
+// replaced with the name of the environment, if any.
+func (v Venv) relocate(oldPath, oldPrompt string) error {
+	newPrompt := v.Name
+	if newPrompt == "" {
+		newPrompt = oldPrompt
+	}
+	replacements := []string{oldPath, v.Path}
+	if newPrompt != oldPrompt {
+		replacements = append(replacements,
+			fmt.Sprintf(`"(%s) `, oldPrompt), fmt.Sprintf(`"(%s) `, newPrompt),
+			fmt.Sprintf(`"%s"`, oldPrompt), fmt.Sprintf(`"%s"`, newPrompt),
+			fmt.Sprintf(`'%s'`, oldPrompt), fmt.Sprintf(`'%s'`, newPrompt),
+		)
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	configPath := filepath.Join(v.Path, PyvenvConfig)
+	files := []string{configPath}
+	execDir := filepath.Join(v.Path, getVenvExecDir())
+	entries, err := os.ReadDir(execDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			files = append(files, filepath.Join(execDir, e.Name()))
+		}
+	}
+	for _, f := range files {
+		err = rewriteFile(f, replacer)
+		if err != nil {
+			return err
+		}
+	}
+	// the prompt only appears in the files above
+	pathReplacer := strings.NewReplacer(oldPath, v.Path)
+	files, err = v.sitePackagesFiles()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		err = rewriteFile(f, pathReplacer)
+		if err != nil {
+			return err
+		}
+	}
+
+	// environments created without --prompt have no prompt key
+	config, err := v.config()
+	if err != nil {
+		return err
+	}
+	if _, ok := config["prompt"]; !ok && newPrompt != oldPrompt {
+		file, err := os.OpenFile(configPath, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(file, "prompt = '%s'\n", newPrompt)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	return nil
+}
+
+// sitePackagesFiles returns the files of the site-packages of the environment
+// that may hold absolute paths: .pth files, egg links, the finders of editable
+// installs and the direct_url.json of the installed distributions.
+func (v Venv) sitePackagesFiles() ([]string, error) {
+	// lib/python3.12/site-packages, or Lib/site-packages on Windows
+	dirs, err := filepath.Glob(filepath.Join(v.Path, "lib", "*", "site-packages"))
+	if err != nil {
+		return nil, err
+	}
+	if winDir := filepath.Join(v.Path, "Lib", "site-packages"); !slices.Contains(dirs, winDir) {
+		if _, err := os.Stat(winDir); err == nil {
+			dirs = append(dirs, winDir)
+		}
+	}
+	files := []string{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			switch {
+			case e.IsDir() && strings.HasSuffix(name, ".dist-info"):
+				directURL := filepath.Join(dir, name, "direct_url.json")
+				if _, err := os.Stat(directURL); err == nil {
+					files = append(files, directURL)
+				}
+			case !e.Type().IsRegular():
+			case strings.HasSuffix(name, ".pth"), strings.HasSuffix(name, ".egg-link"),
+				strings.HasPrefix(name, "__editable__") && strings.HasSuffix(name, ".py"):
+				files = append(files, filepath.Join(dir, name))
+			}
+		}
+	}
+	return files, nil
+}
+
+// rewriteFile applies replacer to the content of a text file. Binary files are
+// left untouched.
+func rewriteFile(path string, replacer *strings.Replacer) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.IndexByte(content, 0) != -1 {
+		return nil
+	}
+	newContent := replacer.Replace(string(content))
+	if newContent == string(content) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(newContent), 0)
+}
+
+// copyDir recursively copies src to dst, preserving file modes and symlinks.
+// Absolute symlinks pointing inside src are rebased onto dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if filepath.IsAbs(link) {
+				if linkRel, err := filepath.Rel(src, link); err == nil && !strings.HasPrefix(linkRel, "..") {
+					link = filepath.Join(dst, linkRel)
+				}
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
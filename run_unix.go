@@ -0,0 +1,51 @@
+//go:build !windows
+
+package venv
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/mattn/go-isatty"
+)
+
+// runForwardingSignals runs command, relaying to it the signals that would
+// terminate vn. When stdin is not a terminal, the command gets a process group
+// of its own and signals are sent to the whole group, so that the processes it
+// spawns stop too. Otherwise it must stay in the foreground process group to
+// use the terminal: the keyboard signals reach it directly, so vn ignores them
+// and only relays the others.
+func runForwardingSignals(command *exec.Cmd) error {
+	ownGroup := !isatty.IsTerminal(os.Stdin.Fd())
+	if ownGroup {
+		command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	err := command.Start()
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				switch {
+				case ownGroup:
+					syscall.Kill(-command.Process.Pid, sig.(syscall.Signal))
+				case sig != syscall.SIGINT && sig != syscall.SIGQUIT:
+					command.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return exitError(command.Wait())
+}
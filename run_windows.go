@@ -0,0 +1,23 @@
+//go:build windows
+
+package venv
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// runForwardingSignals runs command. Ctrl-C reaches every process attached to
+// the console, so vn ignores it and waits for the command to handle it.
+func runForwardingSignals(command *exec.Cmd) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	err := command.Start()
+	if err != nil {
+		return err
+	}
+	return exitError(command.Wait())
+}
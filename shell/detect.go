@@ -0,0 +1,167 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	ps "github.com/mitchellh/go-ps"
+)
+
+// ShellEnv is the environment variable overriding the detected shell. It can
+// be a shell name or the path to a shell executable.
+const ShellEnv = "VN_SHELL"
+
+// methods of detection of the shell
+const (
+	MethodOverride = "override"
+	MethodProcess  = "process"
+	MethodEnv      = "env"
+	MethodFallback = "fallback"
+)
+
+// maxAncestry bounds the walk up the process tree.
+const maxAncestry = 64
+
+// Process is a process inspected during the detection of the shell.
+type Process struct {
+	Pid        int
+	Executable string
+}
+
+// Detection tells which shell has been detected, and how.
+type Detection struct {
+	Shell  Shell
+	Method string
+	// Detail is the variable or the process the shell comes from.
+	Detail string
+	// Ancestry are the processes inspected, from the parent of vn upwards.
+	Ancestry []Process
+}
+
+var (
+	detectOnce sync.Once
+	detection  Detection
+	detectErr  error
+)
+
+// NewShell returns the current shell, as found by Detect.
+func NewShell() (Shell, error) {
+	d, err := Detect()
+	return d.Shell, err
+}
+
+// Detect finds the current shell: the one given by $VN_SHELL, else the closest
+// supported shell among the ancestors of vn, which skips sudo, tmux, script and
+// wrappers, else $SHELL, else the first supported shell available. The result
+// is computed once per process.
+func Detect() (Detection, error) {
+	detectOnce.Do(func() {
+		detection, detectErr = detect()
+	})
+	return detection, detectErr
+}
+
+// detector holds what the detection of the shell depends on, so that it can
+// be replaced in tests.
+type detector struct {
+	getenv func(key string) string
+	// process returns the executable and the parent of the process pid.
+	process func(pid int) (executable string, ppid int, ok bool)
+	// available tells whether the executable of the shell can be found.
+	available func(sh Shell) bool
+	goos      string
+	// ppid is the pid of the parent of vn.
+	ppid int
+}
+
+func detect() (Detection, error) {
+	return detector{
+		getenv:    os.Getenv,
+		process:   findProcess,
+		available: hasShell,
+		goos:      runtime.GOOS,
+		ppid:      os.Getppid(),
+	}.detect()
+}
+
+func findProcess(pid int) (string, int, bool) {
+	proc, err := ps.FindProcess(pid)
+	if err != nil || proc == nil {
+		return "", 0, false
+	}
+	return proc.Executable(), proc.PPid(), true
+}
+
+// named returns the shell of a shell name or executable path, if available.
+func (dt detector) named(executable string) (Shell, error) {
+	sh, err := Named(executable)
+	if err != nil {
+		return Shell{}, err
+	}
+	sh.os = dt.goos
+	if !dt.available(sh) {
+		return Shell{}, fmt.Errorf("shell '%s' is not available", executable)
+	}
+	return sh, nil
+}
+
+func (dt detector) detect() (Detection, error) {
+	if override := dt.getenv(ShellEnv); override != "" {
+		sh, err := dt.named(override)
+		return Detection{Shell: sh, Method: MethodOverride, Detail: ShellEnv + "=" + override}, err
+	}
+
+	d := Detection{}
+	pid := dt.ppid
+	for i := 0; i < maxAncestry && pid > 0; i++ {
+		executable, ppid, ok := dt.process(pid)
+		if !ok {
+			break
+		}
+		d.Ancestry = append(d.Ancestry, Process{Pid: pid, Executable: executable})
+		if sh, err := Named(executable); err == nil {
+			sh.os = dt.goos
+			d.Shell = sh
+			d.Method = MethodProcess
+			d.Detail = fmt.Sprintf("process %d (%s)", pid, executable)
+			return d, nil
+		}
+		if ppid == pid {
+			break
+		}
+		pid = ppid
+	}
+
+	if env := dt.getenv("SHELL"); env != "" {
+		if sh, err := dt.named(env); err == nil {
+			d.Shell = sh
+			d.Method = MethodEnv
+			d.Detail = "SHELL=" + env
+			return d, nil
+		}
+	}
+
+	order := []shellType{bash, zsh, fish, powershell, nushell, tcsh, xonsh, elvish}
+	if dt.goos == "windows" {
+		order = []shellType{powershell, bash, zsh, fish, nushell, tcsh, xonsh, elvish}
+	}
+	for _, name := range order {
+		for _, executable := range shellExecutables[name] {
+			sh := Shell{
+				os:         dt.goos,
+				name:       name,
+				executable: executable,
+			}
+			if dt.available(sh) {
+				d.Shell = sh
+				d.Method = MethodFallback
+				d.Detail = executable
+				return d, nil
+			}
+		}
+	}
+	return d, errors.New("No shell available.")
+}
@@ -0,0 +1,106 @@
+package shell
+
+import (
+	"slices"
+	"testing"
+)
+
+type fakeProcess struct {
+	executable string
+	ppid       int
+}
+
+// fakeDetector returns a detector over the process tree procs, with the shells
+// installed available.
+func fakeDetector(env map[string]string, procs map[int]fakeProcess, installed ...string) detector {
+	return detector{
+		getenv: func(key string) string { return env[key] },
+		process: func(pid int) (string, int, bool) {
+			p, ok := procs[pid]
+			return p.executable, p.ppid, ok
+		},
+		available: func(sh Shell) bool { return slices.Contains(installed, sh.executable) },
+		goos:      "linux",
+		ppid:      100,
+	}
+}
+
+func TestDetect_SelectsTheShell(t *testing.T) {
+	t.Parallel()
+	tree := map[int]fakeProcess{
+		100: {"sudo", 90},
+		90:  {"tmux: server", 80},
+		80:  {"-zsh", 1},
+		1:   {"systemd", 0},
+	}
+	noShell := map[int]fakeProcess{
+		100: {"sshd", 1},
+		1:   {"init", 1},
+	}
+	tests := []struct {
+		name       string
+		env        map[string]string
+		procs      map[int]fakeProcess
+		installed  []string
+		goos       string
+		method     string
+		executable string
+		ancestry   int
+	}{
+		{"override", map[string]string{ShellEnv: "fish", "SHELL": "/bin/bash"}, tree, []string{"fish"}, "linux", MethodOverride, "fish", 0},
+		{"closest ancestor", map[string]string{"SHELL": "/bin/bash"}, tree, []string{"/bin/bash"}, "linux", MethodProcess, "zsh", 3},
+		{"SHELL", map[string]string{"SHELL": "/usr/bin/fish"}, noShell, []string{"/usr/bin/fish", "bash"}, "linux", MethodEnv, "/usr/bin/fish", 2},
+		{"SHELL not installed", map[string]string{"SHELL": "/usr/bin/fish"}, noShell, []string{"zsh"}, "linux", MethodFallback, "zsh", 2},
+		{"first installed", nil, noShell, []string{"fish", "bash"}, "linux", MethodFallback, "bash", 2},
+		{"powershell first on windows", nil, noShell, []string{"bash.exe", "pwsh.exe"}, "windows", MethodFallback, "pwsh.exe", 2},
+	}
+	for _, tt := range tests {
+		dt := fakeDetector(tt.env, tt.procs, tt.installed...)
+		dt.goos = tt.goos
+		d, err := dt.detect()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if d.Method != tt.method || d.Shell.Executable() != tt.executable {
+			t.Errorf("%s: want %s from %s, got %s from %s (%s)", tt.name, tt.executable, tt.method, d.Shell.Executable(), d.Method, d.Detail)
+		}
+		if len(d.Ancestry) != tt.ancestry {
+			t.Errorf("%s: want %d processes inspected, got %v", tt.name, tt.ancestry, d.Ancestry)
+		}
+		if d.Shell.OS() != tt.goos {
+			t.Errorf("%s: want os %s, got %s", tt.name, tt.goos, d.Shell.OS())
+		}
+	}
+}
+
+func TestDetect_FailsWithoutShell(t *testing.T) {
+	t.Parallel()
+	_, err := fakeDetector(map[string]string{ShellEnv: "fish"}, nil).detect()
+	if err == nil {
+		t.Error("want an error for a missing override, got nil")
+	}
+	_, err = fakeDetector(map[string]string{ShellEnv: "python"}, nil, "python").detect()
+	if err == nil {
+		t.Error("want an error for an unsupported override, got nil")
+	}
+	_, err = fakeDetector(nil, nil).detect()
+	if err == nil {
+		t.Error("want an error without shell, got nil")
+	}
+}
+
+func TestDetect_StopsAtProcessLoops(t *testing.T) {
+	t.Parallel()
+	loop := map[int]fakeProcess{
+		100: {"a", 101},
+		101: {"b", 100},
+	}
+	d, err := fakeDetector(nil, loop, "bash").detect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Ancestry) != maxAncestry || d.Method != MethodFallback {
+		t.Errorf("want the walk bounded to %d processes then the fallback, got %d and %s", maxAncestry, len(d.Ancestry), d.Method)
+	}
+}
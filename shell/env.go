@@ -0,0 +1,140 @@
+package shell
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// RestoreEnv records the values replaced by the variables of the active
+// environment, so that deactivation restores them. It holds a JSON object
+// mapping every variable to its previous value, or to null if it was unset.
+const RestoreEnv = "VN_RESTORE_ENV"
+
+// InjectCode returns the code setting vars in the shell, and recording the
+// values they replace in RestoreEnv. It follows DeactivationCode, so values
+// recorded by the previous environment are taken as the previous ones.
+func (s Shell) InjectCode(vars map[string]string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	code := []string{}
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		code = append(code, s.ExportCode(key, vars[key]))
+	}
+	code = append(code, s.ExportCode(RestoreEnv, record(os.Environ(), vars)))
+	return strings.Join(code, "\n") + "\n"
+}
+
+// RestoreCode returns the code restoring the values recorded in RestoreEnv, if
+// any.
+func (s Shell) RestoreCode() string {
+	previous, ok := recorded(os.Environ())
+	if !ok {
+		return ""
+	}
+	code := []string{}
+	for _, key := range slices.Sorted(maps.Keys(previous)) {
+		if previous[key] == nil {
+			code = append(code, s.UnsetCode(key))
+		} else {
+			code = append(code, s.ExportCode(key, *previous[key]))
+		}
+	}
+	code = append(code, s.UnsetCode(RestoreEnv))
+	return strings.Join(code, "\n")
+}
+
+// InjectEnviron returns the KEY=VALUE pairs setting vars in environ, and
+// recording the values they replace in RestoreEnv.
+func InjectEnviron(environ []string, vars map[string]string) []string {
+	if len(vars) == 0 {
+		return nil
+	}
+	injected := []string{}
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		injected = append(injected, key+"="+vars[key])
+	}
+	return append(injected, RestoreEnv+"="+record(environ, vars))
+}
+
+// SetEnviron returns environ with the KEY=VALUE pairs set, replacing the
+// previous values of their keys. Unlike exec.Cmd, syscall.Exec passes
+// duplicated keys on, and most programs only see the first value.
+func SetEnviron(environ []string, pairs ...string) []string {
+	keys := map[string]bool{}
+	for _, p := range pairs {
+		key, _, _ := strings.Cut(p, "=")
+		keys[key] = true
+	}
+	set := slices.DeleteFunc(slices.Clone(environ), func(e string) bool {
+		key, _, _ := strings.Cut(e, "=")
+		return keys[key]
+	})
+	return append(set, pairs...)
+}
+
+// RestoreEnviron returns environ with the values recorded in RestoreEnv
+// restored.
+func RestoreEnviron(environ []string) []string {
+	previous, ok := recorded(environ)
+	if !ok {
+		return environ
+	}
+	restored := []string{}
+	for _, e := range environ {
+		key, _, _ := strings.Cut(e, "=")
+		if _, ok := previous[key]; ok || key == RestoreEnv {
+			continue
+		}
+		restored = append(restored, e)
+	}
+	for _, key := range slices.Sorted(maps.Keys(previous)) {
+		if previous[key] != nil {
+			restored = append(restored, key+"="+*previous[key])
+		}
+	}
+	return restored
+}
+
+// record returns the content of RestoreEnv once vars are set in environ.
+func record(environ []string, vars map[string]string) string {
+	previous, _ := recorded(environ)
+	current := lookup(environ)
+	replaced := map[string]*string{}
+	for key := range vars {
+		if value, ok := previous[key]; ok {
+			replaced[key] = value
+		} else if value, ok := current[key]; ok {
+			replaced[key] = &value
+		} else {
+			replaced[key] = nil
+		}
+	}
+	content, _ := json.Marshal(replaced)
+	return string(content)
+}
+
+// recorded returns the values recorded in RestoreEnv of environ. The boolean
+// is false if there is no record.
+func recorded(environ []string) (map[string]*string, bool) {
+	content, ok := lookup(environ)[RestoreEnv]
+	if !ok {
+		return nil, false
+	}
+	previous := map[string]*string{}
+	// a corrupted record cannot be restored, but is still removed
+	json.Unmarshal([]byte(content), &previous)
+	return previous, true
+}
+
+func lookup(environ []string) map[string]string {
+	vars := map[string]string{}
+	for _, e := range environ {
+		key, value, _ := strings.Cut(e, "=")
+		vars[key] = value
+	}
+	return vars
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os"
+	"syscall"
+)
+
+// Exec replaces the current process with an interactive shell that loads the
+// user's startup files and then script, like Source. environ is the whole
+// environment of the shell.
+func (s Shell) Exec(script string, environ []string) error {
+	command, dir, err := s.command(script, environ)
+	if err == nil {
+		err = syscall.Exec(command.Path, command.Args, command.Env)
+	}
+	// only reached on failure
+	if dir != "" {
+		os.RemoveAll(dir)
+	}
+	return err
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package shell
+
+import "errors"
+
+// Exec replaces the current process with an interactive shell. Processes
+// cannot be replaced on Windows.
+func (s Shell) Exec(script string, environ []string) error {
+	return errors.New("Replacing the shell is not supported on Windows.")
+}
@@ -0,0 +1,109 @@
+package shell
+
+import "fmt"
+
+// inside a shell started by vn activate, vn switch replaces the shell
+const posixSwitch = `    if [ "$1" = switch ] && [ -n "${VN_ACTIVE:-}" ]; then
+        shift
+        command vn switch --check "$@" && exec vn switch "$@"
+        return
+    fi
+`
+
+const fishSwitch = `    if test "$argv[1]" = switch; and set -q VN_ACTIVE
+        command vn switch --check $argv[2..-1]; and exec vn $argv
+        return
+    end
+`
+
+const posixInit = `vn() {
+` + posixSwitch + `    case "$1" in
+    activate|deactivate)
+        case " $* " in
+        *" -h "*|*" --help "*)
+            command vn "$@"
+            return
+            ;;
+        esac
+        local __vn_code
+        __vn_code="$(VN_SHELL=%[1]s command vn "$@" --print)" || return
+        eval "$__vn_code"
+        ;;
+    *)
+        command vn "$@"
+        ;;
+    esac
+}
+`
+
+const fishInit = `function vn
+` + fishSwitch + `    switch "$argv[1]"
+        case activate deactivate
+            if contains -- -h $argv; or contains -- --help $argv
+                command vn $argv
+                return
+            end
+            set -l __vn_code (VN_SHELL=fish command vn $argv --print)
+            or return
+            string join \n -- $__vn_code | source
+        case '*'
+            command vn $argv
+    end
+end
+`
+
+// InitShells are the shells supported by Init and Hook.
+var InitShells = []string{"bash", "zsh", "fish"}
+
+// Init returns the code defining a vn function for the shell name, which
+// evaluates the output of 'vn activate' and 'vn deactivate' in the current
+// shell instead of starting a new one.
+func Init(name string) (string, error) {
+	switch name {
+	case "bash", "zsh":
+		return fmt.Sprintf(posixInit, name), nil
+	case "fish":
+		return fishInit, nil
+	default:
+		return "", fmt.Errorf("unsupported shell '%s'", name)
+	}
+}
+
+const bashHook = `_vn_hook() {
+    local previous_exit_status=$?
+    eval "$(command vn hook-env bash)"
+    return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_vn_hook;"* ]]; then
+    PROMPT_COMMAND="_vn_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`
+
+const zshHook = `_vn_hook() {
+    eval "$(command vn hook-env zsh)"
+}
+typeset -ag precmd_functions
+if (( ! ${precmd_functions[(I)_vn_hook]} )); then
+    precmd_functions=(_vn_hook $precmd_functions)
+fi
+`
+
+const fishHook = `function _vn_hook --on-event fish_prompt
+    command vn hook-env fish | source
+end
+`
+
+// Hook returns the code installing a prompt hook for the shell name, which
+// activates the environment of the current directory as it changes.
+func Hook(name string) (string, error) {
+	switch name {
+	case "bash":
+		return bashHook, nil
+	case "zsh":
+		return zshHook, nil
+	case "fish":
+		return fishHook, nil
+	default:
+		return "", fmt.Errorf("unsupported shell '%s'", name)
+	}
+}
@@ -0,0 +1,69 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInit_DefinesTheVnFunction(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"bash", []string{"vn() {", "VN_SHELL=bash command vn", `eval "$__vn_code"`, "exec vn switch"}},
+		{"zsh", []string{"vn() {", "VN_SHELL=zsh command vn", `eval "$__vn_code"`}},
+		{"fish", []string{"function vn", "VN_SHELL=fish command vn", "| source", "exec vn $argv"}},
+	}
+	for _, tt := range tests {
+		code, err := Init(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range tt.want {
+			if !strings.Contains(code, w) {
+				t.Errorf("%s: want %s in\n%s", tt.name, w, code)
+			}
+		}
+	}
+	if _, err := Init("tcsh"); err == nil {
+		t.Error("want an error for an unsupported shell, got nil")
+	}
+}
+
+func TestInit_EvaluatesActivationInBash(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	// a fake vn printing activation code, and echoing the other commands
+	bin := t.TempDir()
+	fakeVn := `#!/bin/sh
+case "$*" in
+*--print) echo "export ACTIVATED=\"$VN_SHELL $1\"" ;;
+*) echo "ran $*" ;;
+esac
+`
+	err := os.WriteFile(filepath.Join(bin, "vn"), []byte(fakeVn), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Init("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := code + "vn activate -g lib\necho \"$ACTIVATED\"\nvn list\nvn activate --help\n"
+	cmd := exec.Command("bash", "-c", script)
+	cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	want := "bash activate\nran list\nran activate --help\n"
+	if string(output) != want {
+		t.Errorf("want %q, got %q", want, output)
+	}
+}
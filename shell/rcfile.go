@@ -0,0 +1,145 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ActiveEnv marks the shells started by Source with the environment they have
+// activated, so that nested activations can be detected.
+const ActiveEnv = "VN_ACTIVE"
+
+// ActivePidEnv holds the pid of the vn process that started the shell, which is
+// the parent of vn once it has replaced the shell.
+const ActivePidEnv = "VN_ACTIVE_PID"
+
+// the vn function of the startup files runs 'vn switch' in place of the shell,
+// unless the user's startup files define one already
+const posixSwitchFunc = `typeset -f vn >/dev/null 2>&1 || vn() {
+` + posixSwitch + `    command vn "$@"
+}
+`
+
+const fishSwitchFunc = `if not functions -q vn
+function vn
+` + fishSwitch + `    command vn $argv
+end
+end
+`
+
+const bashRc = `if [ -f ~/.bashrc ]; then
+    . ~/.bashrc
+fi
+` + posixSwitchFunc + `. %s
+rm -rf %s
+`
+
+// the user's startup files are loaded with ZDOTDIR restored, in case they
+// refer to it
+const zshEnv = `ZDOTDIR=%s
+if [[ -f "$ZDOTDIR/.zshenv" ]]; then
+    source "$ZDOTDIR/.zshenv"
+fi
+_vn_zdotdir=$ZDOTDIR
+ZDOTDIR=%s
+`
+
+const zshRc = `ZDOTDIR=$_vn_zdotdir
+unset _vn_zdotdir
+if [[ -f "$ZDOTDIR/.zshrc" ]]; then
+    source "$ZDOTDIR/.zshrc"
+fi
+%s
+` + posixSwitchFunc + `source %s
+rm -rf %s
+`
+
+// bashRcfile writes to dir an rcfile that loads the user's ~/.bashrc, then
+// script, and returns its path.
+func bashRcfile(dir, script string) (string, error) {
+	rcfile := filepath.Join(dir, "bashrc")
+	content := fmt.Sprintf(bashRc, quote(bash, script), quote(bash, dir))
+	return rcfile, os.WriteFile(rcfile, []byte(content), 0o600)
+}
+
+// zshShim writes to dir the startup files of a ZDOTDIR that loads the user's
+// startup files, then script.
+func zshShim(dir, script string) error {
+	userDir, ok := os.LookupEnv("ZDOTDIR")
+	restore := ""
+	if !ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		userDir = home
+		restore = `if [[ "$ZDOTDIR" == "$HOME" ]]; then
+    unset ZDOTDIR
+fi`
+	}
+	content := fmt.Sprintf(zshEnv, quote(zsh, userDir), quote(zsh, dir))
+	err := os.WriteFile(filepath.Join(dir, ".zshenv"), []byte(content), 0o600)
+	if err != nil {
+		return err
+	}
+	content = fmt.Sprintf(zshRc, restore, quote(zsh, script), quote(zsh, dir))
+	return os.WriteFile(filepath.Join(dir, ".zshrc"), []byte(content), 0o600)
+}
+
+// xonshRcfiles returns the run control files xonsh loads by default.
+func xonshRcfiles() []string {
+	var candidates []string
+	if rc := os.Getenv("XONSHRC"); rc != "" {
+		candidates = filepath.SplitList(rc)
+	} else {
+		candidates = []string{"/etc/xonsh/xonshrc"}
+		if home, err := os.UserHomeDir(); err == nil {
+			candidates = append(candidates,
+				filepath.Join(home, ".config", "xonsh", "rc.xsh"),
+				filepath.Join(home, ".xonshrc"),
+			)
+		}
+	}
+	rcfiles := []string{}
+	for _, rc := range candidates {
+		if _, err := os.Stat(rc); err == nil {
+			rcfiles = append(rcfiles, rc)
+		}
+	}
+	return rcfiles
+}
+
+// elvishRcfile writes to dir an rcfile made of the user's rc.elv followed by
+// script, and returns its path. elvish has no way to source a file in the
+// global namespace, so the content is copied.
+func elvishRcfile(dir, script string) (string, error) {
+	content := []byte{}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if home, err := os.UserHomeDir(); err == nil {
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		for _, rc := range []string{
+			filepath.Join(configHome, "elvish", "rc.elv"),
+			filepath.Join(home, ".elvish", "rc.elv"),
+		} {
+			if userRc, err := os.ReadFile(rc); err == nil {
+				content = append(userRc, '\n')
+				break
+			}
+		}
+	}
+	activation, err := os.ReadFile(script)
+	if err != nil {
+		return "", err
+	}
+	rcfile := filepath.Join(dir, "rc.elv")
+	cleanup := fmt.Sprintf("\ntry { rm -rf %s } catch { }\n", quote(elvish, dir))
+	return rcfile, os.WriteFile(rcfile, append(append(content, activation...), cleanup...), 0o600)
+}
+
+// fishInitCommand returns the init command of a fish shell sourcing script.
+func fishInitCommand(script string) string {
+	return fishSwitchFunc + "source " + quote(fish, script)
+}
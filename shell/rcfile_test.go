@@ -0,0 +1,136 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommand_LoadsTheStartupFilesThenTheScript(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ZDOTDIR", "")
+	os.Unsetenv("ZDOTDIR")
+	script := "/my venvs/it's/bin/activate"
+	tests := []struct {
+		name string
+		// arguments of the shell, with RC standing for the written rcfile
+		args []string
+		// the written startup files and their expected content
+		files map[string][]string
+		env   string
+	}{
+		{
+			name: "bash",
+			args: []string{"--rcfile", "RC", "-i"},
+			files: map[string][]string{
+				"bashrc": {". ~/.bashrc", `. '/my venvs/it'\''s/bin/activate'`, "rm -rf '", "vn() {"},
+			},
+		},
+		{
+			name: "zsh",
+			args: []string{"-i"},
+			files: map[string][]string{
+				".zshenv": {"ZDOTDIR='" + home + "'", `source "$ZDOTDIR/.zshenv"`},
+				".zshrc":  {`source "$ZDOTDIR/.zshrc"`, "unset ZDOTDIR", `source '/my venvs/it'\''s/bin/activate'`, "rm -rf '"},
+			},
+			env: "ZDOTDIR=",
+		},
+		{
+			name: "fish",
+			args: []string{"--interactive", "--init-command", "RC"},
+		},
+	}
+	for _, tt := range tests {
+		sh := Shell{name: mustNamed(t, tt.name).name, executable: "/bin/sh"}
+		cmd, dir, err := sh.command(script, []string{"A=1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dir != "" {
+			defer os.RemoveAll(dir)
+		}
+		args := cmd.Args[1:]
+		if len(args) != len(tt.args) {
+			t.Fatalf("%s: want arguments %v, got %v", tt.name, tt.args, args)
+		}
+		for i, want := range tt.args {
+			if want != "RC" && args[i] != want {
+				t.Errorf("%s: want arguments %v, got %v", tt.name, tt.args, args)
+			}
+		}
+		for file, wants := range tt.files {
+			content, err := os.ReadFile(filepath.Join(dir, file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range wants {
+				if !strings.Contains(string(content), w) {
+					t.Errorf("%s: want %s in %s:\n%s", tt.name, w, file, content)
+				}
+			}
+		}
+		if tt.name == "bash" && args[1] != filepath.Join(dir, "bashrc") {
+			t.Errorf("bash: want rcfile %s, got %s", filepath.Join(dir, "bashrc"), args[1])
+		}
+		if tt.name == "fish" && !strings.HasSuffix(args[2], `source '/my venvs/it\'s/bin/activate'`) {
+			t.Errorf("fish: want the script sourced last, got %s", args[2])
+		}
+		if !slices.Contains(cmd.Env, "A=1") {
+			t.Errorf("%s: the environment has not been passed: %v", tt.name, cmd.Env)
+		}
+		if tt.env != "" && !slices.Contains(cmd.Env, tt.env+dir) {
+			t.Errorf("%s: want %s in %v", tt.name, tt.env+dir, cmd.Env)
+		}
+	}
+}
+
+func TestCommand_StartsBashWithTheUserRcAndTheScript(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte("FROM_BASHRC=user\nVIRTUAL_ENV=overridden\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(t.TempDir(), "it's", "activate")
+	err = os.Mkdir(filepath.Dir(script), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(script, []byte("VIRTUAL_ENV=venv\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := mustNamed(t, "bash")
+	sh.executable = "bash"
+	cmd, dir, err := sh.command(script, os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stdin = strings.NewReader("echo \"$FROM_BASHRC $VIRTUAL_ENV\"\n")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "user venv\n"; string(output) != want {
+		t.Errorf("want %q, got %q", want, output)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Errorf("the rcfile directory %s has not been removed", dir)
+	}
+}
+
+func mustNamed(t *testing.T, name string) Shell {
+	t.Helper()
+	sh, err := Named(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sh
+}
@@ -0,0 +1,70 @@
+package shell
+
+import "fmt"
+
+// activation scripts of the shells venv ships no script for; they take the
+// environment directory, its bin directory and its prompt
+
+const nushellActivate = `# This file is generated by venv-notary. Use it with "overlay use".
+export-env {
+    let bin = %[2]s
+    let path_name = if 'Path' in $env { 'Path' } else { 'PATH' }
+    let old_path = $env | get $path_name
+    let old_path = if ($old_path | describe) == 'string' { $old_path | split row (char esep) } else { $old_path }
+    load-env {
+        VIRTUAL_ENV: %[1]s
+        VIRTUAL_ENV_PROMPT: %[3]s
+        $path_name: ($old_path | prepend $bin)
+    }
+}
+
+export alias deactivate = overlay hide activate
+`
+
+const xonshActivate = `# This file is generated by venv-notary. Use it with "source".
+$VIRTUAL_ENV = %[1]s
+$VIRTUAL_ENV_PROMPT = %[3]s
+$PATH.insert(0, %[2]s)
+
+
+def _deactivate(args):
+    if %[2]s in $PATH:
+        $PATH.remove(%[2]s)
+    ${...}.pop('VIRTUAL_ENV', None)
+    ${...}.pop('VIRTUAL_ENV_PROMPT', None)
+    del aliases['deactivate']
+
+
+aliases['deactivate'] = _deactivate
+`
+
+const elvishActivate = `# This file is generated by venv-notary.
+var _vn_old_paths = $paths
+set-env VIRTUAL_ENV %[1]s
+set-env VIRTUAL_ENV_PROMPT %[3]s
+set paths = [%[2]s $@paths]
+
+fn deactivate {
+    set paths = $_vn_old_paths
+    unset-env VIRTUAL_ENV
+    unset-env VIRTUAL_ENV_PROMPT
+}
+`
+
+// GenerateActivationScript returns the content of an activation script for the
+// environment at venv, for shells venv ships no script for. bin is the
+// directory of the executables of the environment.
+func (s Shell) GenerateActivationScript(venv, bin, prompt string) (string, error) {
+	var template string
+	switch s.name {
+	case nushell:
+		template = nushellActivate
+	case xonsh:
+		template = xonshActivate
+	case elvish:
+		template = elvishActivate
+	default:
+		return "", fmt.Errorf("cannot generate an activation script for %s", s.Name())
+	}
+	return fmt.Sprintf(template, quote(s.name, venv), quote(s.name, bin), quote(s.name, prompt)), nil
+}
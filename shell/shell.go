@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"runtime"
+	"strings"
 )
 
 type shellType int
@@ -15,13 +15,21 @@ const (
 	zsh
 	fish
 	powershell
+	nushell
+	tcsh
+	xonsh
+	elvish
 )
 
 var shellExecutables = map[shellType][]string{
 	bash:       []string{"bash", "bash.exe"},
 	zsh:        []string{"zsh", "zsh.exe"},
 	fish:       []string{"fish", "fish.exe"},
-	powershell: []string{"pwsh", "powershell", "powershell.exe"},
+	powershell: []string{"pwsh", "pwsh.exe", "powershell", "powershell.exe"},
+	nushell:    []string{"nu", "nu.exe"},
+	tcsh:       []string{"tcsh", "csh"},
+	xonsh:      []string{"xonsh", "xonsh.exe"},
+	elvish:     []string{"elvish", "elvish.exe"},
 }
 
 type Shell struct {
@@ -30,24 +38,90 @@ type Shell struct {
 	executable string
 }
 
-func (s Shell) Source(script string) error {
+// Source starts an interactive shell that loads the user's startup files and
+// then script. env holds additional environment variables of the shell.
+func (s Shell) Source(script string, env ...string) error {
+	command, dir, err := s.command(script, append(os.Environ(), env...))
+	if dir != "" {
+		defer os.RemoveAll(dir)
+	}
+	if err != nil {
+		return err
+	}
+	command.Stderr = os.Stderr
+	command.Stdout = os.Stdout
+	command.Stdin = os.Stdin
+
+	err = command.Run()
+	return err
+}
+
+// command returns the command starting an interactive shell that loads the
+// user's startup files and then script, with the environment environ. dir is
+// the temporary directory holding the startup files written for the shell, if
+// any; the shell removes it once it has started.
+func (s Shell) command(script string, environ []string) (*exec.Cmd, string, error) {
 	var command *exec.Cmd
+	var dir string
+	var err error
 	switch s.name {
-	case bash, zsh:
-		command = exec.Command(s.executable, "-c", fmt.Sprintf("source '%s'; %s", script, s.executable))
+	case bash:
+		dir, err = os.MkdirTemp("", "vn-*")
+		if err != nil {
+			return nil, "", err
+		}
+		rcfile, err := bashRcfile(dir, script)
+		if err != nil {
+			return nil, dir, err
+		}
+		command = exec.Command(s.executable, "--rcfile", rcfile, "-i")
+	case zsh:
+		dir, err = os.MkdirTemp("", "vn-*")
+		if err != nil {
+			return nil, "", err
+		}
+		err = zshShim(dir, script)
+		if err != nil {
+			return nil, dir, err
+		}
+		command = exec.Command(s.executable, "-i")
+		environ = SetEnviron(environ, "ZDOTDIR="+dir)
 	case fish:
-		command = exec.Command(s.executable, "--interactive", "-C", fmt.Sprintf("source '%s'", script))
+		// init commands run after the user's configuration
+		command = exec.Command(s.executable, "--interactive", "--init-command", fishInitCommand(script))
 	case powershell:
-		command = exec.Command(s.executable, "-NoExit", "-ExecutionPolicy", "Bypass", "-Command", fmt.Sprintf(". '%s'", script))
+		command = exec.Command(s.executable, "-NoExit", "-ExecutionPolicy", "Bypass", "-Command", ". "+quote(powershell, script))
+	case nushell:
+		// commands given with --execute run after the user's configuration
+		command = exec.Command(s.executable, "--execute", "overlay use "+quote(nushell, script))
+	case tcsh:
+		// tcsh has no way to run commands after its startup files: the
+		// environment is inherited by a new shell, but not the aliases
+		command = exec.Command(s.executable, "-c", fmt.Sprintf("source %s; exec %s", quote(tcsh, script), quote(tcsh, s.executable)))
+	case xonsh:
+		command = exec.Command(s.executable, append(append([]string{"--rc"}, xonshRcfiles()...), script, "-i")...)
+	case elvish:
+		dir, err = os.MkdirTemp("", "vn-*")
+		if err != nil {
+			return nil, "", err
+		}
+		rcfile, err := elvishRcfile(dir, script)
+		if err != nil {
+			return nil, dir, err
+		}
+		command = exec.Command(s.executable, "-rc", rcfile)
 	default:
-		return errors.New("No shell available.")
+		return nil, "", errors.New("No shell available.")
 	}
-	command.Stderr = os.Stderr
-	command.Stdout = os.Stdout
-	command.Stdin = os.Stdin
+	if command.Err != nil {
+		return nil, dir, command.Err
+	}
+	command.Env = environ
+	return command, dir, nil
+}
 
-	err := command.Run()
-	return err
+func (s Shell) Executable() string {
+	return s.executable
 }
 
 func (s Shell) OS() string {
@@ -64,48 +138,138 @@ func (s Shell) Name() string {
 		return "Fish"
 	case powershell:
 		return "Powershell"
+	case nushell:
+		return "Nushell"
+	case tcsh:
+		return "Tcsh"
+	case xonsh:
+		return "Xonsh"
+	case elvish:
+		return "Elvish"
 	default:
 		return "Unknown"
 	}
 }
 
-func NewShell() (Shell, error) {
-	currOs := runtime.GOOS
-
-	var availableShells = []Shell{}
-	for name, executables := range shellExecutables {
-		for _, executable := range executables {
-			sh := Shell{
-				os:         currOs,
-				name:       name,
-				executable: executable,
-			}
-			if hasShell(sh) {
-				availableShells = append(availableShells, sh)
-			}
-		}
+func (s Shell) GetActivationScript() string {
+	switch s.name {
+	case bash, zsh:
+		return "activate"
+	case fish:
+		return "activate.fish"
+	case powershell:
+		return "Activate.ps1"
+	case nushell:
+		return "activate.nu"
+	case tcsh:
+		return "activate.csh"
+	case xonsh:
+		return "activate.xsh"
+	case elvish:
+		return "activate.elv"
+	default:
+		return ""
 	}
+}
 
-	currentShell, err := getShellName(availableShells)
-
-	if err != nil && len(availableShells) > 0 {
-		currentShell = availableShells[0]
-	} else if err != nil {
-		return Shell{}, err
+// ActivationCode returns the code that sources script in the current shell,
+// deactivating the active environment first.
+func (s Shell) ActivationCode(script string) (string, error) {
+	switch s.name {
+	case bash, zsh, powershell:
+		return fmt.Sprintf("%s\n. %s\n", s.DeactivationCode(), quote(s.name, script)), nil
+	case fish, tcsh, xonsh:
+		return fmt.Sprintf("%s\nsource %s\n", s.DeactivationCode(), quote(s.name, script)), nil
+	case nushell:
+		// nushell cannot evaluate code: the overlay replaces the active one
+		return fmt.Sprintf("overlay use %s\n", quote(s.name, script)), nil
+	case elvish:
+		return fmt.Sprintf("%s\neval (slurp < %s)\n", s.DeactivationCode(), quote(s.name, script)), nil
+	default:
+		return "", errors.New("No shell available.")
 	}
-
-	return currentShell, nil
 }
 
-func (s Shell) GetActivationScript() string {
+// DeactivationCode returns the code that deactivates the active environment
+// of the current shell, if any, and restores the variables it replaced.
+func (s Shell) DeactivationCode() string {
+	var code string
 	switch s.name {
 	case bash, zsh:
-		return "activate"
+		code = "if typeset -f deactivate >/dev/null 2>&1; then deactivate; fi"
 	case fish:
-		return "activate.fish"
+		code = "functions -q deactivate; and deactivate"
 	case powershell:
-		return "Activate.ps1"
+		code = "if (Get-Command deactivate -ErrorAction SilentlyContinue) { deactivate }"
+	case nushell:
+		code = "overlay hide activate"
+	case tcsh:
+		code = "if ( $?VIRTUAL_ENV ) deactivate"
+	case xonsh:
+		code = "aliases['deactivate']([]) if 'deactivate' in aliases else None"
+	case elvish:
+		code = "try { deactivate } catch { }"
 	default:
 		return ""
 	}
+	if restore := s.RestoreCode(); restore != "" {
+		code += "\n" + restore
+	}
+	return code
+}
+
+// ExportCode returns the code setting the environment variable key to value.
+func (s Shell) ExportCode(key, value string) string {
+	switch s.name {
+	case fish:
+		return fmt.Sprintf("set -gx %s %s", key, quote(s.name, value))
+	case powershell:
+		return fmt.Sprintf("$env:%s = %s", key, quote(s.name, value))
+	case nushell:
+		return fmt.Sprintf("$env.%s = %s", key, quote(s.name, value))
+	case tcsh:
+		return fmt.Sprintf("setenv %s %s", key, quote(s.name, value))
+	case xonsh:
+		return fmt.Sprintf("$%s = %s", key, quote(s.name, value))
+	case elvish:
+		return fmt.Sprintf("set-env %s %s", key, quote(s.name, value))
+	default:
+		return fmt.Sprintf("export %s=%s", key, quote(s.name, value))
+	}
+}
+
+// UnsetCode returns the code removing the environment variable key.
+func (s Shell) UnsetCode(key string) string {
+	switch s.name {
+	case fish:
+		return fmt.Sprintf("set -e %s", key)
+	case powershell:
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", key)
+	case nushell:
+		return fmt.Sprintf("hide-env -i %s", key)
+	case tcsh:
+		return fmt.Sprintf("unsetenv %s", key)
+	case xonsh:
+		return fmt.Sprintf("${...}.pop('%s', None)", key)
+	case elvish:
+		return fmt.Sprintf("unset-env %s", key)
+	default:
+		return fmt.Sprintf("unset %s", key)
+	}
+}
+
+// quote quotes s as a single word for the shell.
+func quote(sh shellType, s string) string {
+	switch sh {
+	case fish, xonsh:
+		s = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+	case powershell, elvish:
+		s = strings.ReplaceAll(s, `'`, `''`)
+	case nushell:
+		// raw strings can hold any quote
+		return "r#'" + s + "'#"
+	default:
+		s = strings.ReplaceAll(s, `'`, `'\''`)
+	}
+	return "'" + s + "'"
 }
@@ -0,0 +1,136 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestQuote_EscapesSpacesAndQuotes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		sh   shellType
+		in   string
+		want string
+	}{
+		{bash, "/my venvs/lib", `'/my venvs/lib'`},
+		{bash, "/it's/lib", `'/it'\''s/lib'`},
+		{zsh, "/it's/lib", `'/it'\''s/lib'`},
+		{tcsh, "/it's/lib", `'/it'\''s/lib'`},
+		{fish, `/it's\lib`, `'/it\'s\\lib'`},
+		{xonsh, "/it's/lib", `'/it\'s/lib'`},
+		{powershell, "/it's/lib", `'/it''s/lib'`},
+		{elvish, "/my venvs/it's", `'/my venvs/it''s'`},
+		{nushell, `/it's "lib"`, `r#'/it's "lib"'#`},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.sh, tt.in); got != tt.want {
+			t.Errorf("%d %q: want %s, got %s", tt.sh, tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestQuote_IsASingleWordForBash(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	for _, s := range []string{"/my venvs/lib", "/it's/lib", `/a "b" $HOME \n`, "'", ""} {
+		output, err := exec.Command("bash", "-c", "printf %s "+quote(bash, s)).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != s {
+			t.Errorf("want %q, got %q", s, output)
+		}
+	}
+}
+
+func TestActivationCode_SourcesTheScript(t *testing.T) {
+	t.Parallel()
+	script := "/my venvs/it's/bin/activate"
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"bash", []string{"then deactivate; fi", `. '/my venvs/it'\''s/bin/activate'`}},
+		{"zsh", []string{"then deactivate; fi", `. '/my venvs/it'\''s/bin/activate'`}},
+		{"fish", []string{"and deactivate", `source '/my venvs/it\'s/bin/activate'`}},
+		{"pwsh", []string{"{ deactivate }", `. '/my venvs/it''s/bin/activate'`}},
+		{"tcsh", []string{"if ( $?VIRTUAL_ENV ) deactivate", `source '/my venvs/it'\''s/bin/activate'`}},
+		{"nu", []string{`overlay use r#'/my venvs/it's/bin/activate'#`}},
+		{"elvish", []string{"try { deactivate }", `eval (slurp < '/my venvs/it''s/bin/activate')`}},
+	}
+	for _, tt := range tests {
+		sh, err := Named(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, err := sh.ActivationCode(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range tt.want {
+			if !strings.Contains(code, w) {
+				t.Errorf("%s: want %s in\n%s", tt.name, w, code)
+			}
+		}
+		if deactivation := sh.DeactivationCode(); tt.name != "nu" && !strings.HasPrefix(code, deactivation) {
+			t.Errorf("%s: want the deactivation code first, got\n%s", tt.name, code)
+		}
+	}
+}
+
+func TestActivationCode_ReplacesTheActiveVenvInBash(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "it's a venv")
+	err := os.Mkdir(dir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "activate")
+	err = os.WriteFile(script, []byte("VIRTUAL_ENV=new\ndeactivate() { echo deactivated $VIRTUAL_ENV; }\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := Named("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := sh.ActivationCode(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := "VIRTUAL_ENV=old\ndeactivate() { echo deactivated $VIRTUAL_ENV; unset -f deactivate; }\n"
+	output, err := exec.Command("bash", "-c", old+code+"echo active $VIRTUAL_ENV").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "deactivated old\nactive new\n"; string(output) != want {
+		t.Errorf("want %q, got %q", want, output)
+	}
+	// nothing to deactivate
+	output, err = exec.Command("bash", "-c", sh.DeactivationCode()+"\necho done").CombinedOutput()
+	if err != nil || string(output) != "done\n" {
+		t.Errorf("want a no-op without active venv, got %q (%v)", output, err)
+	}
+}
+
+func TestSetEnviron_ReplacesTheValues(t *testing.T) {
+	t.Parallel()
+	environ := []string{"A=1", "B=2", "A=3", "C=4"}
+	got := SetEnviron(environ, "A=5", "D=6")
+	want := []string{"B=2", "C=4", "A=5", "D=6"}
+	if !slices.Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if environ[0] != "A=1" {
+		t.Errorf("the environment has been modified: %v", environ)
+	}
+}
@@ -1,11 +1,12 @@
 package shell
 
 import (
-	"errors"
-	"os"
+	"fmt"
 	"os/exec"
-
-	ps "github.com/mitchellh/go-ps"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 )
 
 var shellVariables = map[shellType]string{
@@ -15,33 +16,27 @@ var shellVariables = map[shellType]string{
 	powershell: "PSEdition",
 }
 
-func hasShell(shellName Shell) bool {
-	var command *exec.Cmd
-	switch shellName.name {
-	case bash, fish, zsh:
-		command = exec.Command(shellName.executable, "--version")
-	case powershell:
-		command = exec.Command(shellName.executable, "-H")
-	}
-	_, err := command.CombinedOutput()
-	if err != nil {
-		return false
-	}
-	return true
+// hasShell reports whether the executable of the shell can be found. The shell
+// is not run, so that checking is cheap.
+func hasShell(sh Shell) bool {
+	_, err := exec.LookPath(sh.executable)
+	return err == nil
 }
 
-func getShellName(availableShells []Shell) (Shell, error) {
-	ppid := os.Getppid()
-	proc, err := ps.FindProcess(ppid)
-	if err != nil {
-		return Shell{}, err
-	}
-
-	executable := proc.Executable()
-	for _, sh := range availableShells {
-		if executable == sh.executable {
-			return sh, nil
+// Named returns the shell of a shell name, process name or executable path,
+// without checking that it is available.
+func Named(executable string) (Shell, error) {
+	// login shells are named after their executable with a leading dash
+	executable = strings.TrimLeft(executable, "-")
+	base := strings.ToLower(filepath.Base(executable))
+	for name, executables := range shellExecutables {
+		if slices.Contains(executables, base) {
+			return Shell{
+				os:         runtime.GOOS,
+				name:       name,
+				executable: executable,
+			}, nil
 		}
 	}
-	return Shell{}, errors.New("couldn't detect current shell among the supported ones")
+	return Shell{}, fmt.Errorf("unsupported shell '%s'", executable)
 }
@@ -0,0 +1,207 @@
+package venv
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// specifierPattern matches version specifiers: an optional implementation,
+// followed by a version or by comma-separated version clauses, such as 3.12,
+// py3.11, pypy3.10 or >=3.10,<3.13.
+var specifierPattern = regexp.MustCompile(`^(py|cpython|pypy|graalpy)?((~=|==|!=|>=|<=|>|<)?\s*[0-9]+(\.[0-9]+){0,2}[td]*)(\s*,\s*(~=|==|!=|>=|<=|>|<)\s*[0-9]+(\.[0-9]+){0,2}[td]*)*$`)
+
+// executableVersion matches the executables named after their version, such as
+// python3.12 or python3.13t.
+var executableVersion = regexp.MustCompile(`^python([0-9]+\.[0-9]+[td]*)(\.exe)?$`)
+
+// Specifier selects Python versions, and optionally an implementation.
+type Specifier struct {
+	// Implementation is cpython, pypy or graalpy, or empty for any.
+	Implementation string
+	// ABIFlags selects free-threaded (t) or debug (d) builds. Regular builds
+	// are selected if empty.
+	ABIFlags string
+	clauses  []clause
+}
+
+type clause struct {
+	operator string
+	version  []int
+}
+
+// IsSpecifier tells whether python is a version specifier rather than an
+// executable.
+func IsSpecifier(python string) bool {
+	return specifierPattern.MatchString(strings.TrimSpace(python))
+}
+
+// ParseSpecifier parses a version specifier such as 3.12, py3.11, pypy3.10,
+// 3.13t or >=3.10,<3.13. A bare version matches all its patch levels.
+func ParseSpecifier(python string) (Specifier, error) {
+	python = strings.TrimSpace(python)
+	match := specifierPattern.FindStringSubmatch(python)
+	if match == nil {
+		return Specifier{}, fmt.Errorf("Invalid Python version specifier '%s'.", python)
+	}
+	spec := Specifier{Implementation: match[1]}
+	if spec.Implementation == "py" {
+		spec.Implementation = ""
+	}
+	for _, c := range strings.Split(python[len(match[1]):], ",") {
+		c = strings.TrimSpace(c)
+		version := strings.TrimLeft(c, "~=!<>")
+		operator := strings.TrimSpace(c[:len(c)-len(version)])
+		if operator == "" {
+			operator = "=="
+		}
+		version = strings.TrimSpace(version)
+		number := strings.TrimRight(version, "td")
+		spec.ABIFlags += version[len(number):]
+		spec.clauses = append(spec.clauses, clause{operator: operator, version: versionParts(number)})
+	}
+	return spec, nil
+}
+
+// Matches tells whether a build satisfies the specifier. Its version may lack
+// the patch level, in which case it is compared on the components it has for
+// equality.
+func (s Specifier) Matches(build PythonBuild) bool {
+	if s.Implementation != "" && s.Implementation != build.Implementation {
+		return false
+	}
+	flags := PythonBuild{ABIFlags: s.ABIFlags}.tagFlags()
+	if flags != build.tagFlags() {
+		return false
+	}
+	parts := versionParts(build.Version)
+	for _, c := range s.clauses {
+		if !c.matches(parts) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c clause) matches(version []int) bool {
+	switch c.operator {
+	case "==":
+		return hasPrefix(version, c.version)
+	case "!=":
+		return !hasPrefix(version, c.version)
+	case ">=":
+		return compareVersions(version, c.version) >= 0
+	case "<=":
+		return compareVersions(version, c.version) <= 0 || hasPrefix(version, c.version)
+	case ">":
+		// without patch level, 3.12 counts as 3.12.0: it doesn't match >3.12.3
+		return compareVersions(version, c.version) > 0
+	case "<":
+		return compareVersions(version, c.version) < 0
+	case "~=":
+		// compatible release: ~=3.10 is >=3.10,==3.*
+		return compareVersions(version, c.version) >= 0 && hasPrefix(version, c.version[:max(len(c.version)-1, 1)])
+	}
+	return false
+}
+
+// hasPrefix tells whether version and prefix agree on the components they both
+// have, so that 3.12 matches 3.12.4 both ways.
+func hasPrefix(version, prefix []int) bool {
+	for i := 0; i < min(len(version), len(prefix)); i++ {
+		if version[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares versions, missing components counting as 0.
+func compareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	parts := []int{}
+	for _, p := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// ResolvePython returns the executable of the best installed interpreter
+// matching python if it is a version specifier, and python itself otherwise.
+// The best interpreter is the one with the highest version, CPython being
+// preferred when the specifier names no implementation.
+func (n Notary) ResolvePython(python string) (string, error) {
+	if !IsSpecifier(python) {
+		return python, nil
+	}
+	spec, err := ParseSpecifier(python)
+	if err != nil {
+		return "", err
+	}
+	interpreters, err := n.Interpreters()
+	if err != nil {
+		return "", err
+	}
+	// interpreters are sorted by implementation, cpython first, then by
+	// decreasing version, so the first match is the best
+	for _, interpreter := range interpreters {
+		if spec.Matches(interpreter.PythonBuild) {
+			return interpreter.Path, nil
+		}
+	}
+	return "", fmt.Errorf("No installed Python interpreter matches '%s'. Run 'vn pythons' to list the installed interpreters.", python)
+}
+
+// PythonMatcher returns a function telling whether the registered environment
+// at a path has been created with python, a version specifier or an
+// executable. Environments are matched by their recorded version, so that
+// environments whose interpreter is no longer installed are matched too: an
+// executable that cannot be run is matched by the version in its name, as with
+// python3.12. The function matches every environment if python is empty.
+func (n Notary) PythonMatcher(python string) (func(venvPath string) bool, error) {
+	if python == "" {
+		return func(string) bool { return true }, nil
+	}
+	if IsSpecifier(python) {
+		spec, err := ParseSpecifier(python)
+		if err != nil {
+			return nil, err
+		}
+		return func(venvPath string) bool {
+			return spec.Matches(n.Build(venvPath))
+		}, nil
+	}
+	version, err := PythonVersion(python)
+	if err != nil {
+		named := executableVersion.FindStringSubmatch(filepath.Base(python))
+		if named == nil {
+			return nil, err
+		}
+		version = VersionPrefix + named[1]
+	}
+	return func(venvPath string) bool {
+		_, venvVersion := ExtractVersion(filepath.Base(venvPath))
+		return venvVersion == version
+	}, nil
+}
@@ -0,0 +1,90 @@
+package venv
+
+import (
+	"errors"
+	"path"
+	"testing"
+)
+
+func TestParseSpecifier_MatchesVersions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		specifier      string
+		implementation string
+		version        string
+		abiFlags       string
+		want           bool
+	}{
+		{"3.12", "cpython", "3.12.4", "", true},
+		{"3.12", "cpython", "3.1.2", "", false},
+		{"py3.11", "cpython", "3.11", "", true},
+		{"py3.11", "pypy", "3.11.9", "", true},
+		{">=3.10,<3.13", "cpython", "3.12.1", "", true},
+		{">=3.10, <3.13", "cpython", "3.13.0", "", false},
+		{">=3.10,<3.13", "cpython", "3.9.18", "", false},
+		{"<=3.12", "cpython", "3.12.7", "", true},
+		{">3.12", "cpython", "3.12.7", "", true},
+		{">3.12", "cpython", "3.12", "", false},
+		{">3.12.3", "cpython", "3.12", "", false},
+		{">3.12.3", "cpython", "3.13", "", true},
+		{"!=3.11", "cpython", "3.11.2", "", false},
+		{"~=3.10", "cpython", "3.13.0", "", true},
+		{"~=3.10", "cpython", "4.0.0", "", false},
+		{"pypy3.10", "pypy", "3.10.14", "", true},
+		{"pypy3.10", "cpython", "3.10.14", "", false},
+		{"3.13", "cpython", "3.13.0", "t", false},
+		{"3.13t", "cpython", "3.13.0", "t", true},
+		{"3.13t", "cpython", "3.13.0", "", false},
+		{"3.7", "cpython", "3.7.17", "m", true},
+	}
+	for _, tt := range tests {
+		spec, err := ParseSpecifier(tt.specifier)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.specifier, err)
+		}
+		build := PythonBuild{Implementation: tt.implementation, Version: tt.version, ABIFlags: tt.abiFlags}
+		if got := spec.Matches(build); got != tt.want {
+			t.Errorf("%s matching %+v: want %v, got %v", tt.specifier, build, tt.want, got)
+		}
+	}
+	for _, executable := range []string{"python3.12", "/usr/bin/python3", "python", "./3.12"} {
+		if IsSpecifier(executable) {
+			t.Errorf("%s: want an executable, got a specifier", executable)
+		}
+	}
+}
+
+func TestFindGlobal_MatchesVenvsWhoseInterpreterIsGone(t *testing.T) {
+	t.Parallel()
+	notary := Notary{venvDir: t.TempDir()}
+	err := notary.SetUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// registered by hand: no interpreter is run to match the versions
+	notary.venvList = map[string]Location{}
+	notary.metadata = map[string]Metadata{}
+	for _, name := range []string{"lib-py3.9", "lib-py3.12"} {
+		notary.venvList[path.Join(notary.GlobalDir(), name)] = GlobalLoc
+	}
+	notary.metadata[path.Join(notary.GlobalDir(), "lib-py3.12")] = Metadata{Python: "/gone/python3.12", Version: "3.12.4"}
+
+	for _, python := range []string{"3.12", "py3.12", "==3.12.4", ">=3.10", "/gone/python3.12"} {
+		venv, err := notary.FindGlobal("lib", python)
+		if err != nil {
+			t.Errorf("%s: %v", python, err)
+			continue
+		}
+		if got := path.Base(venv.Path); got != "lib-py3.12" {
+			t.Errorf("%s: want lib-py3.12, got %s", python, got)
+		}
+	}
+	_, err = notary.FindGlobal("lib", "3.12.5")
+	if !errors.As(err, &VenvNotRegisteredError{}) {
+		t.Errorf("want VenvNotRegisteredError, got %v", err)
+	}
+	_, err = notary.FindGlobal("lib", ">=3")
+	if !errors.As(err, &MultipleVersionsError{}) {
+		t.Errorf("want MultipleVersionsError, got %v", err)
+	}
+}
@@ -5,15 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 )
 
 const (
-	HASHLEN = 64
+	HASHLEN          = 64
+	VariantSeparator = "+"
 )
 
 // allowed characters: a-z, 0-9, _, -
@@ -44,24 +43,19 @@ func getMinorVersion(version string) string {
 	return strings.Join(parts[:2], ".")
 }
 
+// PythonVersion returns the version suffix of the environments created with a
+// python executable, e.g. "py3.12", "py3.13t" or "pypy3.10".
 func PythonVersion(executable string) (string, error) {
-	cmd := exec.Command(executable, "-V")
-	version, err := cmd.CombinedOutput()
+	build, err := ProbePython(executable)
 	if err != nil {
 		return "", err
 	}
-	versionEls := strings.Fields(string(version))
-	if len(versionEls) != 2 {
-		return "", fmt.Errorf("Something went wrong in fetching python version: '%s'", string(version))
-	}
-	if versionEls[0] != "Python" {
-		return "", errors.New("Executable is not Python binary.")
-	}
-	versionNo := getMinorVersion(versionEls[1])
-	return fmt.Sprintf("py%s", versionNo), nil
+	return build.Tag(), nil
 }
 
-func createLocalName(currDir string) (string, error) {
+// createLocalName returns the name of the local environment of currDir. A
+// non-empty variant names one of several environments of the same directory.
+func createLocalName(currDir, variant string) (string, error) {
 	headDir := NormalizeName(filepath.Base(currDir))
 	if headDir == "" {
 		return "", errors.New("Invalid venv name. Please use a name that contains only letters, digits, '_' and '-'.")
@@ -72,6 +66,13 @@ func createLocalName(currDir string) (string, error) {
 		return "", err
 	}
 	venvName := fmt.Sprintf("%s-%x", headDir, h.Sum(nil))
+	if variant != "" {
+		normalizedVariant := NormalizeName(variant)
+		if normalizedVariant == "" {
+			return "", errors.New("Invalid venv name. Please use a name that contains only letters, digits, '_' and '-'.")
+		}
+		venvName += VariantSeparator + normalizedVariant
+	}
 
 	return venvName, nil
 }
@@ -81,32 +82,47 @@ func addVersion(venv Venv) (Venv, error) {
 	if err != nil {
 		return venv, err
 	}
+	return withVersion(venv, version), nil
+}
+
+func withVersion(venv Venv, version string) Venv {
 	venv.Path = fmt.Sprintf("%s-%s", venv.Path, version)
 	venv.Name = fmt.Sprintf("%s-%s", venv.Name, version)
 
-	return venv, nil
+	return venv
 }
 
+// RemoveHash removes the hash from the name of a local environment, keeping
+// its variant, if any.
 func RemoveHash(name string) string {
+	name, variant := SplitVariant(name)
 	hashLength := HASHLEN + 1
 	if len(name) > hashLength {
 		name = name[:len(name)-(HASHLEN+1)]
 	}
+	if variant != "" {
+		name += VariantSeparator + variant
+	}
 	return name
 }
 
+// SplitVariant splits the name of a local environment, without version, into
+// the name of the directory and the variant. The separator cannot appear in
+// normalized names, so the split is never ambiguous.
+func SplitVariant(name string) (string, string) {
+	base, variant, _ := strings.Cut(name, VariantSeparator)
+	return base, variant
+}
+
+// ExtractVersion splits the name of an environment into the name without
+// version and the version suffix, e.g. "py3.12" or "pypy3.10". The suffix
+// starts at the last "-py", or "-graalpy", of the name.
 func ExtractVersion(name string) (string, string) {
-	separator := "-" + VersionPrefix
-	parts := strings.Split(name, separator)
-	length := len(parts)
-	if length == 1 {
-		return name, ""
-	}
-	version := VersionPrefix + parts[length-1]
-	if name[len(name)-len(version):] != version {
+	start := max(strings.LastIndex(name, "-"+VersionPrefix), strings.LastIndex(name, "-"+GraalPy))
+	if start == -1 {
 		return name, ""
 	}
-	return strings.Join(parts[:length-1], separator), version
+	return name[:start], name[start+1:]
 }
 
 func SafeDir(f func() error) error {
@@ -149,32 +165,35 @@ func AlphanumericSort(a, b string) int {
 	return -1
 }
 
-func SemanticVersioningSort(a, b string) int {
-	partsA := strings.Split(a, ".")
-	partsB := strings.Split(b, ".")
-	for i, e := range partsA {
-		if i >= len(partsB) {
-			return -1
-		}
-		valA, err := strconv.Atoi(e)
-		if err != nil {
-			return -1
-		}
-		valB, err := strconv.Atoi(partsB[i])
-		if err != nil {
-			return 1
-		}
-		if valA > valB {
-			return 1
-		}
-		if valA < valB {
-			return -1
-		}
+// NotaryHome returns the root directory of the notary: $VN_HOME if set,
+// otherwise the venv-notary directory in the user's data directory.
+func NotaryHome() (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return filepath.Abs(home)
 	}
-	if len(partsA) == len(partsB) {
-		return 0
+	dataHome, err := getDataHome()
+	if err != nil {
+		return "", err
 	}
-	return 1
+	return filepath.Join(dataHome, NotaryDir), nil
+}
+
+// ConfigDir returns the directory holding the venv-notary configuration.
+func ConfigDir() (string, error) {
+	configHome, err := getConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, NotaryDir), nil
+}
+
+// CacheDir returns the directory holding the venv-notary caches.
+func CacheDir() (string, error) {
+	cacheHome, err := getCacheHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheHome, NotaryDir), nil
 }
 
 func getDataHome() (string, error) {
@@ -208,6 +227,69 @@ func getDataHome() (string, error) {
 	return dataHome, nil
 }
 
+func getConfigHome() (string, error) {
+	var configHome string
+	switch runtime.GOOS {
+	case "linux":
+		configHome = os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			configHome = filepath.Join(home, ".config")
+		}
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, "Library", "Application Support")
+	case "windows":
+		configHome = os.Getenv("APPDATA")
+		if configHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			configHome = filepath.Join(home, "AppData", "Roaming")
+		}
+	}
+	return configHome, nil
+}
+
+func getCacheHome() (string, error) {
+	var cacheHome string
+	switch runtime.GOOS {
+	case "linux":
+		cacheHome = os.Getenv("XDG_CACHE_HOME")
+		if cacheHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			cacheHome = filepath.Join(home, ".cache")
+		}
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cacheHome = filepath.Join(home, "Library", "Caches")
+	case "windows":
+		cacheHome = os.Getenv("LOCALAPPDATA")
+		if cacheHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			cacheHome = filepath.Join(home, "AppData", "Local")
+		}
+		cacheHome = filepath.Join(cacheHome, "cache")
+	}
+	return cacheHome, nil
+}
+
 func getVenvExecDir() string {
 	var execDir string
 	switch runtime.GOOS {
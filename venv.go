@@ -7,7 +7,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/azr4e1/venv-notary/shell"
 )
@@ -59,32 +62,110 @@ func (v Venv) IsVenv() bool {
 	return true
 }
 
+// CreateOptions changes how an environment is created. They are stored in the
+// metadata of the environment, except for the creator backend, which is
+// recorded once selected.
+type CreateOptions struct {
+	// Creator is the preferred creator backend, and may be empty. Another
+	// backend is used if it is missing or lacks an option.
+	Creator string `json:"-"`
+	// SystemSitePackages gives the environment access to the site-packages
+	// of the interpreter.
+	SystemSitePackages bool `json:"system_site_packages,omitempty"`
+	// WithoutPip skips the installation of pip.
+	WithoutPip bool `json:"without_pip,omitempty"`
+	// Copies copies the interpreter instead of linking it.
+	Copies bool `json:"copies,omitempty"`
+	// UpgradeDeps upgrades pip and its dependencies to their latest version.
+	UpgradeDeps bool `json:"upgrade_deps,omitempty"`
+	// Prompt replaces the name of the environment in the shell prompt.
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Flags returns the options as the command line flags of vn create, which are
+// those of the venv module.
+func (o CreateOptions) Flags() []string {
+	flags := []string{}
+	if o.SystemSitePackages {
+		flags = append(flags, "--system-site-packages")
+	}
+	if o.WithoutPip {
+		flags = append(flags, "--without-pip")
+	}
+	if o.Copies {
+		flags = append(flags, "--copies")
+	}
+	if o.UpgradeDeps {
+		flags = append(flags, "--upgrade-deps")
+	}
+	if o.Prompt != "" {
+		flags = append(flags, "--prompt", o.Prompt)
+	}
+	return flags
+}
+
 func (v Venv) Create() error {
+	return v.CreateWith(CreateOptions{})
+}
+
+// CreateWith creates the environment with opts.
+func (v Venv) CreateWith(opts CreateOptions) error {
+	_, err := v.create(opts)
+	return err
+}
+
+// create creates the environment, and returns the creator backend used.
+func (v Venv) create(opts CreateOptions) (Creator, error) {
 	_, err := os.Stat(v.Path)
 
 	if err != nil {
 		if !errors.Is(err, fs.ErrNotExist) {
-			return err
+			return nil, err
 		}
 	} else {
-		return errors.New("Directory or file already exists with this name.")
+		return nil, errors.New("Directory or file already exists with this name.")
+	}
+	if opts.WithoutPip && opts.UpgradeDeps {
+		return nil, errors.New("Dependencies cannot be upgraded in an environment without pip.")
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = v.Name
 	}
 	executable := v.Python
 	if executable == "" {
 		executable = getVenvPythonExec()
 		if executable == "" {
-			return errors.New("couldn't find python binary")
+			return nil, errors.New("couldn't find python binary")
 		}
 	}
-	cmdEls := []string{executable, "-m", "venv"}
-	if v.Name != "" {
-		cmdEls = append(cmdEls, "--prompt", v.Name)
+	creator, err := SelectCreator(executable, opts)
+	if err != nil {
+		return nil, err
 	}
-	cmdEls = append(cmdEls, v.Path)
+	cmdEls := creator.Command(executable, v.Path, opts)
 	cmd := exec.Command(cmdEls[0], cmdEls[1:]...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		os.RemoveAll(v.Path)
+		return nil, fmt.Errorf("%v. Error message: '%s'", strings.TrimSpace(err.Error()), strings.TrimSpace(string(output)))
+	}
+	return creator, nil
+}
+
+// InstallRequirements installs the given requirement files in the environment
+// with pip.
+func (v Venv) InstallRequirements(files ...string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	python := filepath.Join(v.Path, getVenvExecDir(), getVenvPythonExec())
+	cmdEls := []string{python, "-m", "pip", "install"}
+	for _, f := range files {
+		cmdEls = append(cmdEls, "-r", f)
+	}
+	cmd := exec.Command(cmdEls[0], cmdEls[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
 		return fmt.Errorf("%v. Error message: '%s'", strings.TrimSpace(err.Error()), strings.TrimSpace(string(output)))
 	}
 	return nil
@@ -102,10 +183,51 @@ func (v Venv) Delete() error {
 }
 
 func (v Venv) Run(cmd string, args ...string) error {
+	return v.RunWith(RunOptions{}, cmd, args...)
+}
+
+// RunOptions changes how a command runs in an environment.
+type RunOptions struct {
+	// Env holds variables set on top of those of the environment.
+	Env map[string]string
+	// Dir is the working directory of the command. If empty, the command runs
+	// in the current directory.
+	Dir string
+}
+
+// RunWith runs a command in the environment like Run, as changed by opts.
+func (v Venv) RunWith(opts RunOptions, cmd string, args ...string) error {
+	command, err := v.command(opts, cmd, args...)
+	if err != nil {
+		return err
+	}
+	command.Stderr, command.Stdout, command.Stdin = os.Stderr, os.Stdout, os.Stdin
+
+	return runForwardingSignals(command)
+}
+
+// CombinedOutput runs a command in the environment like Run, and returns its
+// standard output and standard error instead of writing them to the terminal.
+func (v Venv) CombinedOutput(cmd string, args ...string) ([]byte, error) {
+	command, err := v.command(RunOptions{}, cmd, args...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := command.CombinedOutput()
+	return output, exitError(err)
+}
+
+// command returns the command cmd, to be run in the environment as changed by
+// opts.
+func (v Venv) command(opts RunOptions, cmd string, args ...string) (*exec.Cmd, error) {
 	execDir := getVenvExecDir()
 	binDir := filepath.Join(v.Path, execDir)
 
-	env := os.Environ()
+	vars, err := v.environ(opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	env := append(os.Environ(), vars...)
 	// Prepend venv bin to PATH
 	env = append(env, "VIRTUAL_ENV="+v.Path)
 	env = append(env, "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
@@ -119,33 +241,175 @@ func (v Venv) Run(cmd string, args ...string) error {
 	}
 	command := exec.Command(cmdPath, args...)
 	command.Env = env
-	command.Stderr, command.Stdout, command.Stdin = os.Stderr, os.Stdout, os.Stdin
+	command.Dir = opts.Dir
+	return command, nil
+}
 
-	return command.Run()
+// exitError converts the error of a command that ran and exited unsuccessfully
+// to an ExitError carrying its exit status.
+func exitError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return ExitError{Code: 128 + int(status.Signal())}
+	}
+	return ExitError{Code: exitErr.ExitCode()}
 }
 
+// Activate starts a new shell with the environment active. Activating from a
+// shell started by Activate is refused, so that shells don't pile up.
 func (v Venv) Activate() error {
-	if !v.IsVenv() {
-		return fmt.Errorf("'%s' is not a python environment!", v.Path)
+	if active := os.Getenv(shell.ActiveEnv); active != "" {
+		return fmt.Errorf("Already in a shell started by vn for '%s'. Exit it before activating another environment.", active)
 	}
-	if v.IsActive() {
-		return errors.New("environment is already active!")
+	activeShell, err := shell.NewShell()
+	if err != nil {
+		return err
+	}
+	activatePath, err := v.activationScript(activeShell)
+	if err != nil {
+		return err
+	}
+	vars, err := v.ReadEnv()
+	if err != nil {
+		return err
+	}
+	env := append(shell.InjectEnviron(os.Environ(), vars),
+		shell.ActiveEnv+"="+v.Path,
+		shell.ActivePidEnv+"="+strconv.Itoa(os.Getpid()),
+	)
+	return activeShell.Source(activatePath, env...)
+}
+
+// Switch replaces the shell started by Activate with a new one, in which the
+// environment is active instead, so that shells are not nested. vn must run in
+// place of that shell, as with 'exec vn switch'.
+func (v Venv) Switch() error {
+	err := v.CheckSwitch()
+	if err != nil {
+		return err
+	}
+	if os.Getenv(shell.ActivePidEnv) != strconv.Itoa(os.Getppid()) {
+		return errors.New("The shell must be replaced to switch environment. Run 'exec vn switch' instead.")
 	}
 	activeShell, err := shell.NewShell()
 	if err != nil {
 		return err
 	}
-	activateScript := activeShell.GetActivationScript()
+	activatePath, err := v.activationScript(activeShell)
+	if err != nil {
+		return err
+	}
+	vars, err := v.ReadEnv()
+	if err != nil {
+		return err
+	}
+	return activeShell.Exec(activatePath, v.switchEnviron(vars))
+}
+
+// switchEnviron returns the environment of the shell replacing the active one,
+// with vars set.
+func (v Venv) switchEnviron(vars map[string]string) []string {
+	environ := deactivatedEnviron()
+	environ = shell.SetEnviron(environ, shell.InjectEnviron(environ, vars)...)
+	return shell.SetEnviron(environ, shell.ActiveEnv+"="+v.Path)
+}
+
+// CheckSwitch returns an error if Switch cannot activate the environment.
+func (v Venv) CheckSwitch() error {
+	if os.Getenv(shell.ActiveEnv) == "" {
+		return errors.New("Not in a shell started by vn activate. Use vn activate instead.")
+	}
+	activeShell, err := shell.NewShell()
+	if err != nil {
+		return err
+	}
+	_, err = v.activationScript(activeShell)
+	return err
+}
+
+// deactivatedEnviron returns the environment of the process without the
+// variables set by the activation of the active environment.
+func deactivatedEnviron() []string {
+	active := os.Getenv("VIRTUAL_ENV")
+	binDir := filepath.Join(active, getVenvExecDir())
+	environ := []string{}
+	for _, e := range shell.RestoreEnviron(os.Environ()) {
+		key, value, _ := strings.Cut(e, "=")
+		switch {
+		case key == "VIRTUAL_ENV" || key == "VIRTUAL_ENV_PROMPT" || key == shell.ActiveEnv:
+			continue
+		case key == "PATH" && active != "":
+			paths := slices.DeleteFunc(filepath.SplitList(value), func(p string) bool {
+				return p == binDir
+			})
+			e = key + "=" + strings.Join(paths, string(os.PathListSeparator))
+		}
+		environ = append(environ, e)
+	}
+	return environ
+}
+
+// ActivationCode returns the code activating the environment in the current
+// shell, to be evaluated by the shell itself.
+func (v Venv) ActivationCode() (string, error) {
+	activeShell, err := shell.NewShell()
+	if err != nil {
+		return "", err
+	}
+	return v.ShellActivationCode(activeShell)
+}
+
+// ShellActivationCode returns the code activating the environment in sh.
+func (v Venv) ShellActivationCode(sh shell.Shell) (string, error) {
+	activatePath, err := v.activationScript(sh)
+	if err != nil {
+		return "", err
+	}
+	code, err := sh.ActivationCode(activatePath)
+	if err != nil {
+		return "", err
+	}
+	vars, err := v.ReadEnv()
+	if err != nil {
+		return "", err
+	}
+	return code + sh.InjectCode(vars), nil
+}
+
+// activationScript returns the path of the activation script of the
+// environment for sh.
+func (v Venv) activationScript(sh shell.Shell) (string, error) {
+	if !v.IsVenv() {
+		return "", fmt.Errorf("'%s' is not a python environment!", v.Path)
+	}
+	if v.IsActive() {
+		return "", errors.New("environment is already active!")
+	}
+	activateScript := sh.GetActivationScript()
 	if activateScript == "" {
-		return errors.New("cannot locate activation script")
+		return "", errors.New("cannot locate activation script")
 	}
 	execDir := getVenvExecDir()
 	if execDir == "" {
-		return errors.New("cannot locate activation script")
+		return "", errors.New("cannot locate activation script")
 	}
 	activatePath := filepath.Join(v.Path, execDir, activateScript)
-	err = activeShell.Source(activatePath)
-	return err
+	_, err := os.Stat(activatePath)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return activatePath, err
+	}
+	// venv ships no script for some shells: write one
+	content, err := sh.GenerateActivationScript(v.Path, filepath.Join(v.Path, execDir), v.prompt())
+	if err != nil {
+		return "", errors.New("cannot locate activation script")
+	}
+	return activatePath, os.WriteFile(activatePath, []byte(content), 0o644)
 }
 
 func (v Venv) IsActive() bool {